@@ -7,6 +7,7 @@ import (
 
 	"github.com/DonBigBon/parser-backend/config"
 	handlers "github.com/DonBigBon/parser-backend/internal/api"
+	"github.com/DonBigBon/parser-backend/internal/parser"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
@@ -17,6 +18,10 @@ func main() {
 		log.Fatal("Error loading config:", err)
 	}
 
+	if err := parser.LoadProfiles(cfg.RulesDir); err != nil {
+		log.Fatal("Error loading rule profiles:", err)
+	}
+
 	db, err := config.ConnectDB(cfg)
 	if err != nil {
 		log.Fatal("Error connecting to database:", err)
@@ -27,6 +32,8 @@ func main() {
 
 	router.HandleFunc("/", handlers.HomeHandler).Methods("GET")
 	router.HandleFunc("/upload", handlers.UploadHandler).Methods("POST")
+	router.HandleFunc("/references", handlers.ReferencesHandler).Methods("POST")
+	router.HandleFunc("/glossary", handlers.GlossaryHandler).Methods("POST")
 	router.HandleFunc("/download", handlers.DownloadHandler).Methods("GET")
 
 	c := cors.New(cors.Options{
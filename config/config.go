@@ -16,6 +16,7 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBPort     string
+	RulesDir   string
 }
 
 func LoadConfig() (*Config, error) {
@@ -30,6 +31,7 @@ func LoadConfig() (*Config, error) {
 		DBPassword: getEnv("DB_PASSWORD", "123123"),
 		DBName:     getEnv("DB_NAME", "ParserDB"),
 		DBPort:     getEnv("DB_PORT", "1433"),
+		RulesDir:   getEnv("RULES_DIR", "./rules"),
 	}
 
 	return config, nil
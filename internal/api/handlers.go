@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/DonBigBon/parser-backend/internal/filehandler"
+	"github.com/DonBigBon/parser-backend/internal/models"
 	"github.com/DonBigBon/parser-backend/internal/parser"
 )
 
@@ -18,53 +22,148 @@ func UploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	r.Body = http.MaxBytesReader(w, r.Body, 10<<20)
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, "File too large", http.StatusBadRequest)
+	src, codeData, ok := parseUpload(w, r)
+	if !ok {
 		return
 	}
 
-	file, handler, err := r.FormFile("document")
+	// Repealed and suspended provisions are exported unless the client asks
+	// for the provisions in force only.
+	opts := models.ExportOptions{IncludeInactive: r.FormValue("inactive") != "drop"}
+
+	csvFiles, err := filehandler.GenerateCSV(codeData, opts)
 	if err != nil {
-		http.Error(w, "Error retrieving file", http.StatusBadRequest)
+		http.Error(w, "Error generating CSV files", http.StatusInternalServerError)
 		return
 	}
-	defer file.Close()
 
-	filePath, err := filehandler.SaveUploadedFile(file, handler.Filename)
+	sqlDump, err := filehandler.GenerateSQLDump(codeData, opts)
 	if err != nil {
-		http.Error(w, "Error saving file", http.StatusInternalServerError)
+		http.Error(w, "Error generating SQL dump", http.StatusInternalServerError)
 		return
 	}
 
-	codeData, err := parser.ParseDocument(filePath)
-	if err != nil {
-		http.Error(w, "Error parsing document: "+err.Error(), http.StatusInternalServerError)
+	response := map[string]interface{}{
+		"message":    "File processed successfully",
+		"csvFiles":   csvFiles,
+		"sqlDump":    sqlDump,
+		"encoding":   src.Encoding,
+		"document":   codeData.Document,
+		"tocFound":   len(codeData.TOC) > 0,
+		"normalized": codeData.Normalized,
+		"warnings":   codeData.Warnings,
+		"parsedData": codeData,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ReferencesHandler parses an uploaded document and lists the provisions
+// that cite the article given in the "article" field.
+func ReferencesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	csvFiles, err := filehandler.GenerateCSV(codeData)
-	if err != nil {
-		http.Error(w, "Error generating CSV files", http.StatusInternalServerError)
+	_, codeData, ok := parseUpload(w, r)
+	if !ok {
 		return
 	}
 
-	sqlDump, err := filehandler.GenerateSQLDump(codeData)
-	if err != nil {
-		http.Error(w, "Error generating SQL dump", http.StatusInternalServerError)
+	article := r.FormValue("article")
+	if article == "" {
+		http.Error(w, "Article parameter is required", http.StatusBadRequest)
 		return
 	}
 
 	response := map[string]interface{}{
-		"message":  "File processed successfully",
-		"csvFiles": csvFiles,
-		"sqlDump":  sqlDump,
+		"article":    article,
+		"references": codeData.CitedBy(article),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// GlossaryHandler parses an uploaded document and returns the terms defined
+// in its definition articles.
+func GlossaryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	_, codeData, ok := parseUpload(w, r)
+	if !ok {
+		return
+	}
+
+	response := map[string]interface{}{
+		"glossary": codeData.Glossary,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// maxUploadNodes bounds the nodes parsed from one upload.
+const maxUploadNodes = 200000
+
+// parseUpload saves the "document" file of a multipart request and parses it
+// with the requested profile, language and strictness. It writes the error
+// response itself and reports whether the caller may go on.
+func parseUpload(w http.ResponseWriter, r *http.Request) (*parser.Source, *models.CodeData, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 10<<20)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		http.Error(w, "File too large", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	file, handler, err := r.FormFile("document")
+	if err != nil {
+		http.Error(w, "Error retrieving file", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	defer file.Close()
+
+	filePath, err := filehandler.SaveUploadedFile(file, handler.Filename)
+	if err != nil {
+		http.Error(w, "Error saving file", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	src, err := parser.ReadFile(filePath)
+	if err != nil {
+		http.Error(w, "Error parsing document: "+err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	opts := parser.Options{
+		Profile:  r.FormValue("profile"),
+		Language: r.FormValue("language"),
+		Strict:   r.FormValue("strict") == "true",
+		MaxNodes: maxUploadNodes,
+	}
+	if r.FormValue("normalize") == "off" {
+		opts.Normalization = &parser.Normalization{}
+	}
+
+	// The parse stops when the client goes away; there is nobody left to
+	// answer then.
+	doc, err := parser.Parse(r.Context(), src, opts)
+	if errors.Is(err, context.Canceled) {
+		return nil, nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	return src, doc.Data, true
+}
+
 func DownloadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -109,6 +208,15 @@ func DownloadHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
+	var options strings.Builder
+	for _, profile := range parser.Profiles() {
+		selected := ""
+		if profile == parser.DefaultProfile {
+			selected = " selected"
+		}
+		fmt.Fprintf(&options, `<option value="%s"%s>%s</option>`, profile, selected, profile)
+	}
+
 	w.Header().Set("Content-Type", "text/html")
 	fmt.Fprintf(w, `
 		<!DOCTYPE html>
@@ -119,10 +227,11 @@ func HomeHandler(w http.ResponseWriter, r *http.Request) {
 		<body>
 			<h1>Загрузка документа для парсинга</h1>
 			<form method="post" action="/upload" enctype="multipart/form-data">
-				<input type="file" name="document" accept=".docx,.doc,.txt,.rtf" required />
+				<input type="file" name="document" accept=".docx,.doc,.txt,.rtf,.pdf,.html,.htm" required />
+				<select name="profile">%s</select>
 				<button type="submit">Загрузить и обработать</button>
 			</form>
 		</body>
 		</html>
-	`)
+	`, options.String())
 }
@@ -42,58 +42,127 @@ func (h *DBHandler) Close() error {
 	return h.db.Close()
 }
 
-func (h *DBHandler) GenerateSQLQueries(data models.ParsedData) []string {
+func (h *DBHandler) GenerateSQLQueries(data models.ParsedData, opts models.ExportOptions) []string {
+	if !opts.IncludeInactive {
+		data = data.ActiveOnly()
+	}
+
 	var queries []string
 
 	for _, part := range data.Parts {
-		query := fmt.Sprintf("INSERT INTO Parts (PartId, NameRu, NameKz) VALUES (%d, N'%s', N'%s');",
-			part.ID, escapeSQLString(part.NameRu), escapeSQLString(part.NameKz))
+		query := fmt.Sprintf("INSERT INTO Parts (PartId, SortKey, NameRu, NameKz, Status, EffectiveDate) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(part.ID), escapeSQLString(part.SortKey), escapeSQLString(part.NameRu), escapeSQLString(part.NameKz),
+			escapeSQLString(string(part.Status)), escapeSQLString(part.EffectiveDate))
 		queries = append(queries, query)
 	}
 
 	for _, section := range data.Sections {
-		query := fmt.Sprintf("INSERT INTO Sections (SectionId, ParentPartId, NameRu, NameKz) VALUES (%d, %d, N'%s', N'%s');",
-			section.ID, section.ParentPartID, escapeSQLString(section.NameRu), escapeSQLString(section.NameKz))
+		query := fmt.Sprintf("INSERT INTO Sections (SectionId, SortKey, ParentPartId, NameRu, NameKz, Status, EffectiveDate) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(section.ID), escapeSQLString(section.SortKey), escapeSQLString(section.ParentPartID), escapeSQLString(section.NameRu), escapeSQLString(section.NameKz),
+			escapeSQLString(string(section.Status)), escapeSQLString(section.EffectiveDate))
 		queries = append(queries, query)
 	}
 
 	for _, chapter := range data.Chapters {
-		query := fmt.Sprintf("INSERT INTO Chapters (ChapterId, ParentSectionId, ParentPartId, NameRu, NameKz) VALUES (%d, %d, %d, N'%s', N'%s');",
-			chapter.ID, chapter.ParentSectionID, chapter.ParentPartID, escapeSQLString(chapter.NameRu), escapeSQLString(chapter.NameKz))
+		query := fmt.Sprintf("INSERT INTO Chapters (ChapterId, SortKey, ParentSectionId, ParentPartId, NameRu, NameKz, Status, EffectiveDate) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(chapter.ID), escapeSQLString(chapter.SortKey), escapeSQLString(chapter.ParentSectionID), escapeSQLString(chapter.ParentPartID), escapeSQLString(chapter.NameRu), escapeSQLString(chapter.NameKz),
+			escapeSQLString(string(chapter.Status)), escapeSQLString(chapter.EffectiveDate))
 		queries = append(queries, query)
 	}
 
 	for _, paragraph := range data.Paragraphs {
-		query := fmt.Sprintf("INSERT INTO Paragraphs (ParagraphId, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz) VALUES (%d, %d, %d, %d, N'%s', N'%s');",
-			paragraph.ID, paragraph.ParentChapterID, paragraph.ParentSectionID, paragraph.ParentPartID,
-			escapeSQLString(paragraph.NameRu), escapeSQLString(paragraph.NameKz))
+		query := fmt.Sprintf("INSERT INTO Paragraphs (ParagraphId, SortKey, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz, Status, EffectiveDate) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(paragraph.ID), escapeSQLString(paragraph.SortKey), escapeSQLString(paragraph.ParentChapterID), escapeSQLString(paragraph.ParentSectionID), escapeSQLString(paragraph.ParentPartID),
+			escapeSQLString(paragraph.NameRu), escapeSQLString(paragraph.NameKz),
+			escapeSQLString(string(paragraph.Status)), escapeSQLString(paragraph.EffectiveDate))
 		queries = append(queries, query)
 	}
 
 	for _, article := range data.Articles {
-		query := fmt.Sprintf("INSERT INTO Articles (ArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz) VALUES (%d, %d, %d, %d, %d, N'%s', N'%s');",
-			article.ID, article.ParentParagraphID, article.ParentChapterID, article.ParentSectionID, article.ParentPartID,
-			escapeSQLString(article.NameRu), escapeSQLString(article.NameKz))
+		query := fmt.Sprintf("INSERT INTO Articles (ArticleId, SortKey, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(article.ID), escapeSQLString(article.SortKey), escapeSQLString(article.ParentParagraphID), escapeSQLString(article.ParentChapterID), escapeSQLString(article.ParentSectionID), escapeSQLString(article.ParentPartID),
+			escapeSQLString(article.NameRu), escapeSQLString(article.NameKz), escapeSQLString(article.TextRu), escapeSQLString(article.TextKz),
+			escapeSQLString(string(article.Status)), escapeSQLString(article.EffectiveDate))
+		queries = append(queries, query)
+	}
+
+	for _, point := range data.Points {
+		query := fmt.Sprintf("INSERT INTO Points (PointId, SortKey, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(point.ID), escapeSQLString(point.SortKey), escapeSQLString(point.ParentArticleID), escapeSQLString(point.ParentParagraphID), escapeSQLString(point.ParentChapterID), escapeSQLString(point.ParentSectionID), escapeSQLString(point.ParentPartID),
+			escapeSQLString(point.NameRu), escapeSQLString(point.NameKz), escapeSQLString(point.TextRu), escapeSQLString(point.TextKz),
+			escapeSQLString(string(point.Status)), escapeSQLString(point.EffectiveDate))
 		queries = append(queries, query)
 	}
 
 	for _, clause := range data.Clauses {
-		query := fmt.Sprintf("INSERT INTO Clauses (ClauseId, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz) VALUES (%d, %d, %d, %d, %d, %d, N'%s', N'%s');",
-			clause.ID, clause.ParentArticleID, clause.ParentParagraphID, clause.ParentChapterID, clause.ParentSectionID, clause.ParentPartID,
-			escapeSQLString(clause.NameRu), escapeSQLString(clause.NameKz))
+		query := fmt.Sprintf("INSERT INTO Clauses (ClauseId, SortKey, ParentPointId, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(clause.ID), escapeSQLString(clause.SortKey), escapeSQLString(clause.ParentPointID), escapeSQLString(clause.ParentArticleID), escapeSQLString(clause.ParentParagraphID), escapeSQLString(clause.ParentChapterID), escapeSQLString(clause.ParentSectionID), escapeSQLString(clause.ParentPartID),
+			escapeSQLString(clause.NameRu), escapeSQLString(clause.NameKz), escapeSQLString(clause.TextRu), escapeSQLString(clause.TextKz),
+			escapeSQLString(string(clause.Status)), escapeSQLString(clause.EffectiveDate))
 		queries = append(queries, query)
 	}
 
 	for _, subClause := range data.SubClauses {
-		query := fmt.Sprintf("INSERT INTO SubClauses (SubClauseId, ParentClauseId, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz) VALUES (%d, %d, %d, %d, %d, %d, %d, N'%s', N'%s');",
-			subClause.ID, subClause.ParentClauseID, subClause.ParentArticleID, subClause.ParentParagraphID, subClause.ParentChapterID, subClause.ParentSectionID, subClause.ParentPartID,
-			escapeSQLString(subClause.NameRu), escapeSQLString(subClause.NameKz))
+		query := fmt.Sprintf("INSERT INTO SubClauses (SubClauseId, SortKey, ParentClauseId, ParentPointId, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(subClause.ID), escapeSQLString(subClause.SortKey), escapeSQLString(subClause.ParentClauseID), escapeSQLString(subClause.ParentPointID), escapeSQLString(subClause.ParentArticleID), escapeSQLString(subClause.ParentParagraphID), escapeSQLString(subClause.ParentChapterID), escapeSQLString(subClause.ParentSectionID), escapeSQLString(subClause.ParentPartID),
+			escapeSQLString(subClause.NameRu), escapeSQLString(subClause.NameKz), escapeSQLString(subClause.TextRu), escapeSQLString(subClause.TextKz),
+			escapeSQLString(string(subClause.Status)), escapeSQLString(subClause.EffectiveDate))
+		queries = append(queries, query)
+	}
+
+	for _, note := range data.Notes {
+		query := fmt.Sprintf("INSERT INTO Notes (NodeType, NodeNumber, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, Change, ActType, ActDate, ActNumber, Text) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(note.NodeType), escapeSQLString(note.NodeNumber), escapeSQLString(note.ParentArticleID), escapeSQLString(note.ParentParagraphID), escapeSQLString(note.ParentChapterID), escapeSQLString(note.ParentSectionID), escapeSQLString(note.ParentPartID),
+			escapeSQLString(note.Change), escapeSQLString(note.ActType), escapeSQLString(note.ActDate), escapeSQLString(note.ActNumber), escapeSQLString(note.Text))
+		queries = append(queries, query)
+	}
+
+	for _, reference := range data.References {
+		query := fmt.Sprintf("INSERT INTO [References] (NodeType, NodeNumber, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, TargetType, TargetNumber, TargetClauseId, TargetPointId, TargetArticleId, TargetParagraphId, TargetChapterId, TargetSectionId, TargetPartId, Resolved, External, Text) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', %d, N'%s', N'%s');",
+			escapeSQLString(reference.NodeType), escapeSQLString(reference.NodeNumber), escapeSQLString(reference.ParentArticleID), escapeSQLString(reference.ParentParagraphID), escapeSQLString(reference.ParentChapterID), escapeSQLString(reference.ParentSectionID), escapeSQLString(reference.ParentPartID),
+			escapeSQLString(reference.TargetType), escapeSQLString(reference.TargetNumber), escapeSQLString(reference.TargetClauseID), escapeSQLString(reference.TargetPointID), escapeSQLString(reference.TargetArticleID), escapeSQLString(reference.TargetParagraphID), escapeSQLString(reference.TargetChapterID), escapeSQLString(reference.TargetSectionID), escapeSQLString(reference.TargetPartID),
+			sqlBit(reference.Resolved), escapeSQLString(reference.External), escapeSQLString(reference.Text))
+		queries = append(queries, query)
+	}
+
+	for _, term := range data.Glossary {
+		query := fmt.Sprintf("INSERT INTO Glossary (NodeType, NodeNumber, ParentPointId, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId, Language, Term, Definition) VALUES (N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s', N'%s');",
+			escapeSQLString(term.NodeType), escapeSQLString(term.NodeNumber), escapeSQLString(term.ParentPointID), escapeSQLString(term.ParentArticleID), escapeSQLString(term.ParentParagraphID), escapeSQLString(term.ParentChapterID), escapeSQLString(term.ParentSectionID), escapeSQLString(term.ParentPartID),
+			escapeSQLString(term.Language), escapeSQLString(term.Term), escapeSQLString(term.Definition))
 		queries = append(queries, query)
 	}
 
 	return queries
 }
 
+// CitingReferences answers "what cites article X" from the stored
+// reference table.
+func (h *DBHandler) CitingReferences(articleID string) ([]models.Reference, error) {
+	rows, err := h.db.Query(`SELECT NodeType, NodeNumber, ParentArticleId, ParentParagraphId, ParentChapterId, ParentSectionId, ParentPartId,
+		TargetType, TargetNumber, TargetClauseId, TargetPointId, TargetArticleId, External, Text
+		FROM [References]
+		WHERE Resolved = 1 AND ((TargetType = 'ARTICLE' AND TargetNumber = @p1) OR TargetArticleId = @p1)`, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying references: %v", err)
+	}
+	defer rows.Close()
+
+	var refs []models.Reference
+	for rows.Next() {
+		ref := models.Reference{Resolved: true}
+		err := rows.Scan(&ref.NodeType, &ref.NodeNumber, &ref.ParentArticleID, &ref.ParentParagraphID, &ref.ParentChapterID, &ref.ParentSectionID, &ref.ParentPartID,
+			&ref.TargetType, &ref.TargetNumber, &ref.TargetClauseID, &ref.TargetPointID, &ref.TargetArticleID, &ref.External, &ref.Text)
+		if err != nil {
+			return nil, fmt.Errorf("error reading reference: %v", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading references: %v", err)
+	}
+	return refs, nil
+}
+
 func (h *DBHandler) ExecuteQueries(queries []string) error {
 	for _, query := range queries {
 		_, err := h.db.Exec(query)
@@ -104,6 +173,13 @@ func (h *DBHandler) ExecuteQueries(queries []string) error {
 	return nil
 }
 
+func sqlBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func escapeSQLString(s string) string {
 	return strings.ReplaceAll(s, "'", "''")
 }
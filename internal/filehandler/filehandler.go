@@ -17,6 +17,9 @@ var supportedFormats = map[string]bool{
 	".doc":  true,
 	".txt":  true,
 	".rtf":  true,
+	".pdf":  true,
+	".html": true,
+	".htm":  true,
 }
 
 func SaveUploadedFile(file io.Reader, filename string) (string, error) {
@@ -48,7 +51,12 @@ func SaveUploadedFile(file io.Reader, filename string) (string, error) {
 	return filePath, nil
 }
 
-func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
+func GenerateCSV(codeData *models.CodeData, opts models.ExportOptions) (map[string]string, error) {
+	if !opts.IncludeInactive {
+		active := codeData.ActiveOnly()
+		codeData = &active
+	}
+
 	csvFiles := make(map[string]string)
 
 	csvDir := "./csv_output"
@@ -61,20 +69,44 @@ func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
 
 	f := excelize.NewFile()
 
-	sheetName := "Parts"
+	// The metadata of the act takes the default first sheet.
+	sheetName := "Document"
+	if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+		return nil, err
+	}
+	f.SetCellValue(sheetName, "A1", "ActType")
+	f.SetCellValue(sheetName, "B1", "Date")
+	f.SetCellValue(sheetName, "C1", "Number")
+	f.SetCellValue(sheetName, "D1", "Title")
+	f.SetCellValue(sheetName, "E1", "Language")
+	f.SetCellValue(sheetName, "A2", codeData.Document.ActType)
+	f.SetCellValue(sheetName, "B2", codeData.Document.Date)
+	f.SetCellValue(sheetName, "C2", codeData.Document.Number)
+	f.SetCellValue(sheetName, "D2", codeData.Document.Title)
+	f.SetCellValue(sheetName, "E2", codeData.Document.Language)
+
+	sheetName = "Parts"
 	index, err := f.NewSheet(sheetName)
 	if err != nil {
 		return nil, err
 	}
 	f.SetCellValue(sheetName, "A1", "PartNumber")
-	f.SetCellValue(sheetName, "B1", "NameRu")
-	f.SetCellValue(sheetName, "C1", "NameKz")
+	f.SetCellValue(sheetName, "B1", "SortKey")
+	f.SetCellValue(sheetName, "C1", "NameRu")
+	f.SetCellValue(sheetName, "D1", "NameKz")
+	f.SetCellValue(sheetName, "E1", "Status")
+	f.SetCellValue(sheetName, "F1", "EffectiveDate")
+	f.SetCellValue(sheetName, "G1", "Source")
 
 	for i, part := range codeData.Parts {
 		row := i + 2
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), part.Number)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), part.NameRu)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), part.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), part.ID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), part.SortKey)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), part.NameRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), part.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), string(part.Status))
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), part.EffectiveDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), sourceDebug(part.Source))
 	}
 
 	sheetName = "Sections"
@@ -84,15 +116,23 @@ func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
 	}
 	f.SetCellValue(sheetName, "A1", "PartNumber")
 	f.SetCellValue(sheetName, "B1", "SectionNumber")
-	f.SetCellValue(sheetName, "C1", "NameRu")
-	f.SetCellValue(sheetName, "D1", "NameKz")
+	f.SetCellValue(sheetName, "C1", "SortKey")
+	f.SetCellValue(sheetName, "D1", "NameRu")
+	f.SetCellValue(sheetName, "E1", "NameKz")
+	f.SetCellValue(sheetName, "F1", "Status")
+	f.SetCellValue(sheetName, "G1", "EffectiveDate")
+	f.SetCellValue(sheetName, "H1", "Source")
 
 	for i, section := range codeData.Sections {
 		row := i + 2
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), section.PartNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), section.Number)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), section.NameRu)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), section.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), section.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), section.ID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), section.SortKey)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), section.NameRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), section.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), string(section.Status))
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), section.EffectiveDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), sourceDebug(section.Source))
 	}
 
 	sheetName = "Chapters"
@@ -103,16 +143,24 @@ func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
 	f.SetCellValue(sheetName, "A1", "PartNumber")
 	f.SetCellValue(sheetName, "B1", "SectionNumber")
 	f.SetCellValue(sheetName, "C1", "ChapterNumber")
-	f.SetCellValue(sheetName, "D1", "NameRu")
-	f.SetCellValue(sheetName, "E1", "NameKz")
+	f.SetCellValue(sheetName, "D1", "SortKey")
+	f.SetCellValue(sheetName, "E1", "NameRu")
+	f.SetCellValue(sheetName, "F1", "NameKz")
+	f.SetCellValue(sheetName, "G1", "Status")
+	f.SetCellValue(sheetName, "H1", "EffectiveDate")
+	f.SetCellValue(sheetName, "I1", "Source")
 
 	for i, chapter := range codeData.Chapters {
 		row := i + 2
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), chapter.PartNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), chapter.SectionNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), chapter.Number)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), chapter.NameRu)
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), chapter.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), chapter.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), chapter.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), chapter.ID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), chapter.SortKey)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), chapter.NameRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), chapter.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), string(chapter.Status))
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), chapter.EffectiveDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), sourceDebug(chapter.Source))
 	}
 
 	sheetName = "Paragraphs"
@@ -124,17 +172,25 @@ func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
 	f.SetCellValue(sheetName, "B1", "SectionNumber")
 	f.SetCellValue(sheetName, "C1", "ChapterNumber")
 	f.SetCellValue(sheetName, "D1", "ParagraphNumber")
-	f.SetCellValue(sheetName, "E1", "NameRu")
-	f.SetCellValue(sheetName, "F1", "NameKz")
+	f.SetCellValue(sheetName, "E1", "SortKey")
+	f.SetCellValue(sheetName, "F1", "NameRu")
+	f.SetCellValue(sheetName, "G1", "NameKz")
+	f.SetCellValue(sheetName, "H1", "Status")
+	f.SetCellValue(sheetName, "I1", "EffectiveDate")
+	f.SetCellValue(sheetName, "J1", "Source")
 
 	for i, paragraph := range codeData.Paragraphs {
 		row := i + 2
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), paragraph.PartNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), paragraph.SectionNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), paragraph.ChapterNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), paragraph.Number)
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), paragraph.NameRu)
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), paragraph.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), paragraph.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), paragraph.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), paragraph.ParentChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), paragraph.ID)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), paragraph.SortKey)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), paragraph.NameRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), paragraph.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), string(paragraph.Status))
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), paragraph.EffectiveDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), sourceDebug(paragraph.Source))
 	}
 
 	sheetName = "Articles"
@@ -147,18 +203,68 @@ func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
 	f.SetCellValue(sheetName, "C1", "ChapterNumber")
 	f.SetCellValue(sheetName, "D1", "ParagraphNumber")
 	f.SetCellValue(sheetName, "E1", "ArticleNumber")
-	f.SetCellValue(sheetName, "F1", "NameRu")
-	f.SetCellValue(sheetName, "G1", "NameKz")
+	f.SetCellValue(sheetName, "F1", "SortKey")
+	f.SetCellValue(sheetName, "G1", "NameRu")
+	f.SetCellValue(sheetName, "H1", "NameKz")
+	f.SetCellValue(sheetName, "I1", "TextRu")
+	f.SetCellValue(sheetName, "J1", "TextKz")
+	f.SetCellValue(sheetName, "K1", "Status")
+	f.SetCellValue(sheetName, "L1", "EffectiveDate")
+	f.SetCellValue(sheetName, "M1", "Source")
 
 	for i, article := range codeData.Articles {
 		row := i + 2
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), article.PartNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), article.SectionNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), article.ChapterNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), article.ParagraphNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), article.Number)
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), article.NameRu)
-		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), article.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), article.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), article.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), article.ParentChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), article.ParentParagraphID)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), article.ID)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), article.SortKey)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), article.NameRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), article.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), article.TextRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), article.TextKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), string(article.Status))
+		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), article.EffectiveDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("M%d", row), sourceDebug(article.Source))
+	}
+
+	sheetName = "Points"
+	index, err = f.NewSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	f.SetCellValue(sheetName, "A1", "PartNumber")
+	f.SetCellValue(sheetName, "B1", "SectionNumber")
+	f.SetCellValue(sheetName, "C1", "ChapterNumber")
+	f.SetCellValue(sheetName, "D1", "ParagraphNumber")
+	f.SetCellValue(sheetName, "E1", "ArticleNumber")
+	f.SetCellValue(sheetName, "F1", "PointNumber")
+	f.SetCellValue(sheetName, "G1", "SortKey")
+	f.SetCellValue(sheetName, "H1", "NameRu")
+	f.SetCellValue(sheetName, "I1", "NameKz")
+	f.SetCellValue(sheetName, "J1", "TextRu")
+	f.SetCellValue(sheetName, "K1", "TextKz")
+	f.SetCellValue(sheetName, "L1", "Status")
+	f.SetCellValue(sheetName, "M1", "EffectiveDate")
+	f.SetCellValue(sheetName, "N1", "Source")
+
+	for i, point := range codeData.Points {
+		row := i + 2
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), point.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), point.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), point.ParentChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), point.ParentParagraphID)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), point.ParentArticleID)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), point.ID)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), point.SortKey)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), point.NameRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), point.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), point.TextRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), point.TextKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), string(point.Status))
+		f.SetCellValue(sheetName, fmt.Sprintf("M%d", row), point.EffectiveDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("N%d", row), sourceDebug(point.Source))
 	}
 
 	sheetName = "Clauses"
@@ -171,20 +277,34 @@ func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
 	f.SetCellValue(sheetName, "C1", "ChapterNumber")
 	f.SetCellValue(sheetName, "D1", "ParagraphNumber")
 	f.SetCellValue(sheetName, "E1", "ArticleNumber")
-	f.SetCellValue(sheetName, "F1", "ClauseNumber")
-	f.SetCellValue(sheetName, "G1", "NameRu")
-	f.SetCellValue(sheetName, "H1", "NameKz")
+	f.SetCellValue(sheetName, "F1", "PointNumber")
+	f.SetCellValue(sheetName, "G1", "ClauseNumber")
+	f.SetCellValue(sheetName, "H1", "SortKey")
+	f.SetCellValue(sheetName, "I1", "NameRu")
+	f.SetCellValue(sheetName, "J1", "NameKz")
+	f.SetCellValue(sheetName, "K1", "TextRu")
+	f.SetCellValue(sheetName, "L1", "TextKz")
+	f.SetCellValue(sheetName, "M1", "Status")
+	f.SetCellValue(sheetName, "N1", "EffectiveDate")
+	f.SetCellValue(sheetName, "O1", "Source")
 
 	for i, clause := range codeData.Clauses {
 		row := i + 2
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), clause.PartNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), clause.SectionNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), clause.ChapterNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), clause.ParagraphNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), clause.ArticleNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), clause.Number)
-		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), clause.NameRu)
-		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), clause.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), clause.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), clause.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), clause.ParentChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), clause.ParentParagraphID)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), clause.ParentArticleID)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), clause.ParentPointID)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), clause.ID)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), clause.SortKey)
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), clause.NameRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), clause.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), clause.TextRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), clause.TextKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("M%d", row), string(clause.Status))
+		f.SetCellValue(sheetName, fmt.Sprintf("N%d", row), clause.EffectiveDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("O%d", row), sourceDebug(clause.Source))
 	}
 
 	sheetName = "SubClauses"
@@ -197,22 +317,180 @@ func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
 	f.SetCellValue(sheetName, "C1", "ChapterNumber")
 	f.SetCellValue(sheetName, "D1", "ParagraphNumber")
 	f.SetCellValue(sheetName, "E1", "ArticleNumber")
-	f.SetCellValue(sheetName, "F1", "ClauseNumber")
-	f.SetCellValue(sheetName, "G1", "SubClauseNumber")
-	f.SetCellValue(sheetName, "H1", "NameRu")
-	f.SetCellValue(sheetName, "I1", "NameKz")
+	f.SetCellValue(sheetName, "F1", "PointNumber")
+	f.SetCellValue(sheetName, "G1", "ClauseNumber")
+	f.SetCellValue(sheetName, "H1", "SubClauseNumber")
+	f.SetCellValue(sheetName, "I1", "SortKey")
+	f.SetCellValue(sheetName, "J1", "NameRu")
+	f.SetCellValue(sheetName, "K1", "NameKz")
+	f.SetCellValue(sheetName, "L1", "TextRu")
+	f.SetCellValue(sheetName, "M1", "TextKz")
+	f.SetCellValue(sheetName, "N1", "Status")
+	f.SetCellValue(sheetName, "O1", "EffectiveDate")
+	f.SetCellValue(sheetName, "P1", "Source")
 
 	for i, subClause := range codeData.SubClauses {
 		row := i + 2
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), subClause.PartNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), subClause.SectionNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), subClause.ChapterNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), subClause.ParagraphNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), subClause.ArticleNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), subClause.ClauseNumber)
-		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), subClause.Number)
-		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), subClause.NameRu)
-		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), subClause.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), subClause.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), subClause.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), subClause.ParentChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), subClause.ParentParagraphID)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), subClause.ParentArticleID)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), subClause.ParentPointID)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), subClause.ParentClauseID)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), subClause.ID)
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), subClause.SortKey)
+		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), subClause.NameRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), subClause.NameKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), subClause.TextRu)
+		f.SetCellValue(sheetName, fmt.Sprintf("M%d", row), subClause.TextKz)
+		f.SetCellValue(sheetName, fmt.Sprintf("N%d", row), string(subClause.Status))
+		f.SetCellValue(sheetName, fmt.Sprintf("O%d", row), subClause.EffectiveDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("P%d", row), sourceDebug(subClause.Source))
+	}
+
+	sheetName = "Notes"
+	index, err = f.NewSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	f.SetCellValue(sheetName, "A1", "PartNumber")
+	f.SetCellValue(sheetName, "B1", "SectionNumber")
+	f.SetCellValue(sheetName, "C1", "ChapterNumber")
+	f.SetCellValue(sheetName, "D1", "ParagraphNumber")
+	f.SetCellValue(sheetName, "E1", "ArticleNumber")
+	f.SetCellValue(sheetName, "F1", "NodeType")
+	f.SetCellValue(sheetName, "G1", "NodeNumber")
+	f.SetCellValue(sheetName, "H1", "Change")
+	f.SetCellValue(sheetName, "I1", "ActType")
+	f.SetCellValue(sheetName, "J1", "ActDate")
+	f.SetCellValue(sheetName, "K1", "ActNumber")
+	f.SetCellValue(sheetName, "L1", "Text")
+	f.SetCellValue(sheetName, "M1", "Source")
+
+	for i, note := range codeData.Notes {
+		row := i + 2
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), note.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), note.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), note.ParentChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), note.ParentParagraphID)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), note.ParentArticleID)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), note.NodeType)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), note.NodeNumber)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), note.Change)
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), note.ActType)
+		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), note.ActDate)
+		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), note.ActNumber)
+		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), note.Text)
+		f.SetCellValue(sheetName, fmt.Sprintf("M%d", row), sourceDebug(note.Source))
+	}
+
+	sheetName = "Glossary"
+	index, err = f.NewSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	f.SetCellValue(sheetName, "A1", "PartNumber")
+	f.SetCellValue(sheetName, "B1", "SectionNumber")
+	f.SetCellValue(sheetName, "C1", "ChapterNumber")
+	f.SetCellValue(sheetName, "D1", "ParagraphNumber")
+	f.SetCellValue(sheetName, "E1", "ArticleNumber")
+	f.SetCellValue(sheetName, "F1", "PointNumber")
+	f.SetCellValue(sheetName, "G1", "NodeType")
+	f.SetCellValue(sheetName, "H1", "NodeNumber")
+	f.SetCellValue(sheetName, "I1", "Language")
+	f.SetCellValue(sheetName, "J1", "Term")
+	f.SetCellValue(sheetName, "K1", "Definition")
+	f.SetCellValue(sheetName, "L1", "Source")
+
+	for i, term := range codeData.Glossary {
+		row := i + 2
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), term.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), term.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), term.ParentChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), term.ParentParagraphID)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), term.ParentArticleID)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), term.ParentPointID)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), term.NodeType)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), term.NodeNumber)
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), term.Language)
+		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), term.Term)
+		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), term.Definition)
+		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), sourceDebug(term.Source))
+	}
+
+	sheetName = "References"
+	index, err = f.NewSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	f.SetCellValue(sheetName, "A1", "PartNumber")
+	f.SetCellValue(sheetName, "B1", "SectionNumber")
+	f.SetCellValue(sheetName, "C1", "ChapterNumber")
+	f.SetCellValue(sheetName, "D1", "ParagraphNumber")
+	f.SetCellValue(sheetName, "E1", "ArticleNumber")
+	f.SetCellValue(sheetName, "F1", "NodeType")
+	f.SetCellValue(sheetName, "G1", "NodeNumber")
+	f.SetCellValue(sheetName, "H1", "TargetType")
+	f.SetCellValue(sheetName, "I1", "TargetNumber")
+	f.SetCellValue(sheetName, "J1", "TargetPartNumber")
+	f.SetCellValue(sheetName, "K1", "TargetSectionNumber")
+	f.SetCellValue(sheetName, "L1", "TargetChapterNumber")
+	f.SetCellValue(sheetName, "M1", "TargetParagraphNumber")
+	f.SetCellValue(sheetName, "N1", "TargetArticleNumber")
+	f.SetCellValue(sheetName, "O1", "TargetPointNumber")
+	f.SetCellValue(sheetName, "P1", "TargetClauseNumber")
+	f.SetCellValue(sheetName, "Q1", "Resolved")
+	f.SetCellValue(sheetName, "R1", "External")
+	f.SetCellValue(sheetName, "S1", "Text")
+	f.SetCellValue(sheetName, "T1", "Source")
+
+	for i, reference := range codeData.References {
+		row := i + 2
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), reference.ParentPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), reference.ParentSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), reference.ParentChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), reference.ParentParagraphID)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), reference.ParentArticleID)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), reference.NodeType)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), reference.NodeNumber)
+		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), reference.TargetType)
+		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), reference.TargetNumber)
+		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), reference.TargetPartID)
+		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), reference.TargetSectionID)
+		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), reference.TargetChapterID)
+		f.SetCellValue(sheetName, fmt.Sprintf("M%d", row), reference.TargetParagraphID)
+		f.SetCellValue(sheetName, fmt.Sprintf("N%d", row), reference.TargetArticleID)
+		f.SetCellValue(sheetName, fmt.Sprintf("O%d", row), reference.TargetPointID)
+		f.SetCellValue(sheetName, fmt.Sprintf("P%d", row), reference.TargetClauseID)
+		f.SetCellValue(sheetName, fmt.Sprintf("Q%d", row), reference.Resolved)
+		f.SetCellValue(sheetName, fmt.Sprintf("R%d", row), reference.External)
+		f.SetCellValue(sheetName, fmt.Sprintf("S%d", row), reference.Text)
+		f.SetCellValue(sheetName, fmt.Sprintf("T%d", row), sourceDebug(reference.Source))
+	}
+
+	sheetName = "Warnings"
+	index, err = f.NewSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	f.SetCellValue(sheetName, "A1", "Kind")
+	f.SetCellValue(sheetName, "B1", "NodeType")
+	f.SetCellValue(sheetName, "C1", "Number")
+	f.SetCellValue(sheetName, "D1", "ParentType")
+	f.SetCellValue(sheetName, "E1", "ParentNumber")
+	f.SetCellValue(sheetName, "F1", "Message")
+	f.SetCellValue(sheetName, "G1", "Line")
+
+	for i, warning := range codeData.Warnings {
+		row := i + 2
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), warning.Kind)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), warning.NodeType)
+		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), warning.Number)
+		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), warning.ParentType)
+		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), warning.ParentNumber)
+		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), warning.Message)
+		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), warning.Line)
 	}
 
 	f.SetActiveSheet(index)
@@ -227,7 +505,12 @@ func GenerateCSV(codeData *models.CodeData) (map[string]string, error) {
 	return csvFiles, nil
 }
 
-func GenerateSQLDump(codeData *models.CodeData) (string, error) {
+func GenerateSQLDump(codeData *models.CodeData, opts models.ExportOptions) (string, error) {
+	if !opts.IncludeInactive {
+		active := codeData.ActiveOnly()
+		codeData = &active
+	}
+
 	sqlDir := "./sql_output"
 	if _, err := os.Stat(sqlDir); os.IsNotExist(err) {
 		err = os.MkdirAll(sqlDir, 0755)
@@ -245,8 +528,12 @@ func GenerateSQLDump(codeData *models.CodeData) (string, error) {
 
 	sql := `
 -- Очистка таблиц
+DELETE FROM Glossary;
+DELETE FROM [References];
+DELETE FROM Notes;
 DELETE FROM SubClauses;
 DELETE FROM Clauses;
+DELETE FROM Points;
 DELETE FROM Articles;
 DELETE FROM Paragraphs;
 DELETE FROM Chapters;
@@ -255,115 +542,199 @@ DELETE FROM Parts;
 DELETE FROM Codes;
 
 -- Создаем новый кодекс
-INSERT INTO Codes (Name) VALUES ('Новый кодекс');
-DECLARE @CodeID INT = SCOPE_IDENTITY();
-
--- Вставка частей
 `
 
+	name := codeData.Document.Title
+	if name == "" {
+		name = "Новый кодекс"
+	}
+	sql += fmt.Sprintf("INSERT INTO Codes (Name, ActType, AdoptionDate, Number, Language) VALUES ('%s', '%s', %s, '%s', '%s');\n",
+		escapeSQLString(name), escapeSQLString(codeData.Document.ActType), sqlDate(codeData.Document.Date),
+		escapeSQLString(codeData.Document.Number), escapeSQLString(codeData.Document.Language))
+	sql += "DECLARE @CodeID INT = SCOPE_IDENTITY();\n\n-- Вставка частей\n"
+
+	// Variables are numbered rather than named after the designation, which
+	// may contain hyphens and repeat when a document is inconsistent.
+	varIndex := 0
 	partIDMap := make(map[string]string)
-	for i, part := range codeData.Parts {
-		partID := fmt.Sprintf("@PartID_%s", part.Number)
-		partIDMap[part.Number] = partID
-
-		sql += fmt.Sprintf("INSERT INTO Parts (CodeID, Number, NameRu, NameKz) VALUES (@CodeID, '%s', '%s', '%s');\n",
-			part.Number, escapeSQLString(part.NameRu), escapeSQLString(part.NameKz))
+	for _, part := range codeData.Parts {
+		varIndex++
+		partID := fmt.Sprintf("@PartID_%d", varIndex)
+		partIDMap[part.ID] = partID
+
+		sql += fmt.Sprintf("INSERT INTO Parts (CodeID, Number, SortKey, NameRu, NameKz, Status, EffectiveDate) VALUES (@CodeID, '%s', '%s', '%s', '%s', '%s', %s);\n",
+			escapeSQLString(part.ID), escapeSQLString(part.SortKey), escapeSQLString(part.NameRu), escapeSQLString(part.NameKz),
+			escapeSQLString(string(part.Status)), sqlDate(part.EffectiveDate))
 		sql += fmt.Sprintf("DECLARE %s INT = SCOPE_IDENTITY();\n\n", partID)
 	}
 
+	// Every row links to all of its ancestors, so a node that skips a level,
+	// such as an article directly in a part, still has its parents.
 	sectionIDMap := make(map[string]string)
-	for i, section := range codeData.Sections {
-		sectionKey := fmt.Sprintf("%s_%s", section.PartNumber, section.Number)
-		sectionID := fmt.Sprintf("@SectionID_%s", sectionKey)
-		sectionIDMap[sectionKey] = sectionID
+	for _, section := range codeData.Sections {
+		varIndex++
+		sectionID := fmt.Sprintf("@SectionID_%d", varIndex)
+		sectionIDMap[sqlKey(section.ParentPartID, section.ID)] = sectionID
 
-		partID := partIDMap[section.PartNumber]
-		if partID == "" {
-			partID = "NULL"
-		}
+		partID := sqlVar(partIDMap, section.ParentPartID)
 
-		sql += fmt.Sprintf("INSERT INTO Sections (PartID, Number, NameRu, NameKz) VALUES (%s, '%s', '%s', '%s');\n",
-			partID, section.Number, escapeSQLString(section.NameRu), escapeSQLString(section.NameKz))
+		sql += fmt.Sprintf("INSERT INTO Sections (PartID, Number, SortKey, NameRu, NameKz, Status, EffectiveDate) VALUES (%s, '%s', '%s', '%s', '%s', '%s', %s);\n",
+			partID, escapeSQLString(section.ID), escapeSQLString(section.SortKey), escapeSQLString(section.NameRu), escapeSQLString(section.NameKz),
+			escapeSQLString(string(section.Status)), sqlDate(section.EffectiveDate))
 		sql += fmt.Sprintf("DECLARE %s INT = SCOPE_IDENTITY();\n\n", sectionID)
 	}
 
 	chapterIDMap := make(map[string]string)
-	for i, chapter := range codeData.Chapters {
-		chapterKey := fmt.Sprintf("%s_%s_%s", chapter.PartNumber, chapter.SectionNumber, chapter.Number)
-		chapterID := fmt.Sprintf("@ChapterID_%s", chapterKey)
-		chapterIDMap[chapterKey] = chapterID
-
-		sectionKey := fmt.Sprintf("%s_%s", chapter.PartNumber, chapter.SectionNumber)
-		sectionID := sectionIDMap[sectionKey]
-		if sectionID == "" {
-			sectionID = "NULL"
-		}
+	for _, chapter := range codeData.Chapters {
+		varIndex++
+		chapterID := fmt.Sprintf("@ChapterID_%d", varIndex)
+		chapterIDMap[sqlKey(chapter.ParentPartID, chapter.ParentSectionID, chapter.ID)] = chapterID
 
-		sql += fmt.Sprintf("INSERT INTO Chapters (SectionID, Number, NameRu, NameKz) VALUES (%s, '%s', '%s', '%s');\n",
-			sectionID, chapter.Number, escapeSQLString(chapter.NameRu), escapeSQLString(chapter.NameKz))
+		sectionID := sqlVar(sectionIDMap, sqlKey(chapter.ParentPartID, chapter.ParentSectionID))
+		partID := sqlVar(partIDMap, chapter.ParentPartID)
+
+		sql += fmt.Sprintf("INSERT INTO Chapters (SectionID, PartID, Number, SortKey, NameRu, NameKz, Status, EffectiveDate) VALUES (%s, %s, '%s', '%s', '%s', '%s', '%s', %s);\n",
+			sectionID, partID, escapeSQLString(chapter.ID), escapeSQLString(chapter.SortKey), escapeSQLString(chapter.NameRu), escapeSQLString(chapter.NameKz),
+			escapeSQLString(string(chapter.Status)), sqlDate(chapter.EffectiveDate))
 		sql += fmt.Sprintf("DECLARE %s INT = SCOPE_IDENTITY();\n\n", chapterID)
 	}
 
 	paragraphIDMap := make(map[string]string)
-	for i, paragraph := range codeData.Paragraphs {
-		paragraphKey := fmt.Sprintf("%s_%s_%s_%s", paragraph.PartNumber, paragraph.SectionNumber, paragraph.ChapterNumber, paragraph.Number)
-		paragraphID := fmt.Sprintf("@ParagraphID_%s", paragraphKey)
-		paragraphIDMap[paragraphKey] = paragraphID
-
-		chapterKey := fmt.Sprintf("%s_%s_%s", paragraph.PartNumber, paragraph.SectionNumber, paragraph.ChapterNumber)
-		chapterID := chapterIDMap[chapterKey]
-		if chapterID == "" {
-			chapterID = "NULL"
-		}
-
-		sql += fmt.Sprintf("INSERT INTO Paragraphs (ChapterID, Number, NameRu, NameKz) VALUES (%s, '%s', '%s', '%s');\n",
-			chapterID, paragraph.Number, escapeSQLString(paragraph.NameRu), escapeSQLString(paragraph.NameKz))
+	for _, paragraph := range codeData.Paragraphs {
+		varIndex++
+		paragraphID := fmt.Sprintf("@ParagraphID_%d", varIndex)
+		paragraphIDMap[sqlKey(paragraph.ParentPartID, paragraph.ParentSectionID, paragraph.ParentChapterID, paragraph.ID)] = paragraphID
+
+		chapterID := sqlVar(chapterIDMap, sqlKey(paragraph.ParentPartID, paragraph.ParentSectionID, paragraph.ParentChapterID))
+		sectionID := sqlVar(sectionIDMap, sqlKey(paragraph.ParentPartID, paragraph.ParentSectionID))
+		partID := sqlVar(partIDMap, paragraph.ParentPartID)
+
+		sql += fmt.Sprintf("INSERT INTO Paragraphs (ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, Status, EffectiveDate) VALUES (%s, %s, %s, '%s', '%s', '%s', '%s', '%s', %s);\n",
+			chapterID, sectionID, partID, escapeSQLString(paragraph.ID), escapeSQLString(paragraph.SortKey), escapeSQLString(paragraph.NameRu), escapeSQLString(paragraph.NameKz),
+			escapeSQLString(string(paragraph.Status)), sqlDate(paragraph.EffectiveDate))
 		sql += fmt.Sprintf("DECLARE %s INT = SCOPE_IDENTITY();\n\n", paragraphID)
 	}
 
 	articleIDMap := make(map[string]string)
-	for i, article := range codeData.Articles {
-		articleKey := fmt.Sprintf("%s_%s_%s_%s_%s", article.PartNumber, article.SectionNumber, article.ChapterNumber, article.ParagraphNumber, article.Number)
-		articleID := fmt.Sprintf("@ArticleID_%s", articleKey)
-		articleIDMap[articleKey] = articleID
-
-		paragraphKey := fmt.Sprintf("%s_%s_%s_%s", article.PartNumber, article.SectionNumber, article.ChapterNumber, article.ParagraphNumber)
-		paragraphID := paragraphIDMap[paragraphKey]
-		if paragraphID == "" {
-			paragraphID = "NULL"
-		}
-
-		sql += fmt.Sprintf("INSERT INTO Articles (ParagraphID, Number, NameRu, NameKz) VALUES (%s, '%s', '%s', '%s');\n",
-			paragraphID, article.Number, escapeSQLString(article.NameRu), escapeSQLString(article.NameKz))
+	for _, article := range codeData.Articles {
+		varIndex++
+		articleID := fmt.Sprintf("@ArticleID_%d", varIndex)
+		articleIDMap[sqlKey(article.ParentPartID, article.ParentSectionID, article.ParentChapterID, article.ParentParagraphID, article.ID)] = articleID
+
+		paragraphID := sqlVar(paragraphIDMap, sqlKey(article.ParentPartID, article.ParentSectionID, article.ParentChapterID, article.ParentParagraphID))
+		chapterID := sqlVar(chapterIDMap, sqlKey(article.ParentPartID, article.ParentSectionID, article.ParentChapterID))
+		sectionID := sqlVar(sectionIDMap, sqlKey(article.ParentPartID, article.ParentSectionID))
+		partID := sqlVar(partIDMap, article.ParentPartID)
+
+		sql += fmt.Sprintf("INSERT INTO Articles (ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (%s, %s, %s, %s, '%s', '%s', '%s', '%s', '%s', '%s', '%s', %s);\n",
+			paragraphID, chapterID, sectionID, partID, escapeSQLString(article.ID), escapeSQLString(article.SortKey), escapeSQLString(article.NameRu), escapeSQLString(article.NameKz),
+			escapeSQLString(article.TextRu), escapeSQLString(article.TextKz),
+			escapeSQLString(string(article.Status)), sqlDate(article.EffectiveDate))
 		sql += fmt.Sprintf("DECLARE %s INT = SCOPE_IDENTITY();\n\n", articleID)
 	}
 
+	pointIDMap := make(map[string]string)
+	for _, point := range codeData.Points {
+		articleKey := sqlKey(point.ParentPartID, point.ParentSectionID, point.ParentChapterID, point.ParentParagraphID, point.ParentArticleID)
+		varIndex++
+		pointID := fmt.Sprintf("@PointID_%d", varIndex)
+		pointIDMap[sqlKey(articleKey, point.ID)] = pointID
+
+		articleID := sqlVar(articleIDMap, articleKey)
+		paragraphID := sqlVar(paragraphIDMap, sqlKey(point.ParentPartID, point.ParentSectionID, point.ParentChapterID, point.ParentParagraphID))
+		chapterID := sqlVar(chapterIDMap, sqlKey(point.ParentPartID, point.ParentSectionID, point.ParentChapterID))
+		sectionID := sqlVar(sectionIDMap, sqlKey(point.ParentPartID, point.ParentSectionID))
+		partID := sqlVar(partIDMap, point.ParentPartID)
+
+		sql += fmt.Sprintf("INSERT INTO Points (ArticleID, ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (%s, %s, %s, %s, %s, '%s', '%s', '%s', '%s', '%s', '%s', '%s', %s);\n",
+			articleID, paragraphID, chapterID, sectionID, partID, escapeSQLString(point.ID), escapeSQLString(point.SortKey), escapeSQLString(point.NameRu), escapeSQLString(point.NameKz),
+			escapeSQLString(point.TextRu), escapeSQLString(point.TextKz),
+			escapeSQLString(string(point.Status)), sqlDate(point.EffectiveDate))
+		sql += fmt.Sprintf("DECLARE %s INT = SCOPE_IDENTITY();\n\n", pointID)
+	}
+
 	clauseIDMap := make(map[string]string)
-	for i, clause := range codeData.Clauses {
-		clauseKey := fmt.Sprintf("%s_%s_%s_%s_%s_%s", clause.PartNumber, clause.SectionNumber, clause.ChapterNumber, clause.ParagraphNumber, clause.ArticleNumber, clause.Number)
-		clauseID := fmt.Sprintf("@ClauseID_%s", clauseKey)
-		clauseIDMap[clauseKey] = clauseID
-
-		articleKey := fmt.Sprintf("%s_%s_%s_%s_%s", clause.PartNumber, clause.SectionNumber, clause.ChapterNumber, clause.ParagraphNumber, clause.ArticleNumber)
-		articleID := articleIDMap[articleKey]
-		if articleID == "" {
-			articleID = "NULL"
+	for _, clause := range codeData.Clauses {
+		articleKey := sqlKey(clause.ParentPartID, clause.ParentSectionID, clause.ParentChapterID, clause.ParentParagraphID, clause.ParentArticleID)
+		varIndex++
+		clauseID := fmt.Sprintf("@ClauseID_%d", varIndex)
+		clauseIDMap[sqlKey(articleKey, clause.ParentPointID, clause.ID)] = clauseID
+
+		pointID := sqlVar(pointIDMap, sqlKey(articleKey, clause.ParentPointID))
+		articleID := sqlVar(articleIDMap, articleKey)
+		paragraphID := sqlVar(paragraphIDMap, sqlKey(clause.ParentPartID, clause.ParentSectionID, clause.ParentChapterID, clause.ParentParagraphID))
+		chapterID := sqlVar(chapterIDMap, sqlKey(clause.ParentPartID, clause.ParentSectionID, clause.ParentChapterID))
+		sectionID := sqlVar(sectionIDMap, sqlKey(clause.ParentPartID, clause.ParentSectionID))
+		partID := sqlVar(partIDMap, clause.ParentPartID)
+
+		sql += fmt.Sprintf("INSERT INTO Clauses (ArticleID, PointID, ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (%s, %s, %s, %s, %s, %s, '%s', '%s', '%s', '%s', '%s', '%s', '%s', %s);\n",
+			articleID, pointID, paragraphID, chapterID, sectionID, partID, escapeSQLString(clause.ID), escapeSQLString(clause.SortKey), escapeSQLString(clause.NameRu), escapeSQLString(clause.NameKz),
+			escapeSQLString(clause.TextRu), escapeSQLString(clause.TextKz),
+			escapeSQLString(string(clause.Status)), sqlDate(clause.EffectiveDate))
+		sql += fmt.Sprintf("DECLARE %s INT = SCOPE_IDENTITY();\n\n", clauseID)
+	}
+
+	for _, subClause := range codeData.SubClauses {
+		articleKey := sqlKey(subClause.ParentPartID, subClause.ParentSectionID, subClause.ParentChapterID, subClause.ParentParagraphID, subClause.ParentArticleID)
+		clauseID := sqlVar(clauseIDMap, sqlKey(articleKey, subClause.ParentPointID, subClause.ParentClauseID))
+		pointID := sqlVar(pointIDMap, sqlKey(articleKey, subClause.ParentPointID))
+		articleID := sqlVar(articleIDMap, articleKey)
+		paragraphID := sqlVar(paragraphIDMap, sqlKey(subClause.ParentPartID, subClause.ParentSectionID, subClause.ParentChapterID, subClause.ParentParagraphID))
+		chapterID := sqlVar(chapterIDMap, sqlKey(subClause.ParentPartID, subClause.ParentSectionID, subClause.ParentChapterID))
+		sectionID := sqlVar(sectionIDMap, sqlKey(subClause.ParentPartID, subClause.ParentSectionID))
+		partID := sqlVar(partIDMap, subClause.ParentPartID)
+
+		sql += fmt.Sprintf("INSERT INTO SubClauses (ClauseID, PointID, ArticleID, ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (%s, %s, %s, %s, %s, %s, %s, '%s', '%s', '%s', '%s', '%s', '%s', '%s', %s);\n",
+			clauseID, pointID, articleID, paragraphID, chapterID, sectionID, partID, escapeSQLString(subClause.ID), escapeSQLString(subClause.SortKey), escapeSQLString(subClause.NameRu), escapeSQLString(subClause.NameKz),
+			escapeSQLString(subClause.TextRu), escapeSQLString(subClause.TextKz),
+			escapeSQLString(string(subClause.Status)), sqlDate(subClause.EffectiveDate))
+	}
+
+	for _, note := range codeData.Notes {
+		// Notes are linked to the article they sit in, or describe.
+		articleNumber := note.ParentArticleID
+		if note.NodeType == "ARTICLE" {
+			articleNumber = note.NodeNumber
 		}
+		articleID := sqlVar(articleIDMap, sqlKey(note.ParentPartID, note.ParentSectionID, note.ParentChapterID, note.ParentParagraphID, articleNumber))
 
-		sql += fmt.Sprintf("INSERT INTO Clauses (ArticleID, Number, NameRu, NameKz) VALUES (%s, '%s', '%s', '%s');\n",
-			articleID, clause.Number, escapeSQLString(clause.NameRu), escapeSQLString(clause.NameKz))
-		sql += fmt.Sprintf("DECLARE %s INT = SCOPE_IDENTITY();\n\n", clauseID)
+		sql += fmt.Sprintf("INSERT INTO Notes (CodeID, ArticleID, NodeType, NodeNumber, Change, ActType, ActDate, ActNumber, Text) VALUES (@CodeID, %s, '%s', '%s', '%s', '%s', %s, '%s', '%s');\n",
+			articleID, escapeSQLString(note.NodeType), escapeSQLString(note.NodeNumber), escapeSQLString(note.Change),
+			escapeSQLString(note.ActType), sqlDate(note.ActDate), escapeSQLString(note.ActNumber), escapeSQLString(note.Text))
 	}
 
-	for i, subClause := range codeData.SubClauses {
-		clauseKey := fmt.Sprintf("%s_%s_%s_%s_%s_%s", subClause.PartNumber, subClause.SectionNumber, subClause.ChapterNumber, subClause.ParagraphNumber, subClause.ArticleNumber, subClause.ClauseNumber)
-		clauseID := clauseIDMap[clauseKey]
-		if clauseID == "" {
-			clauseID = "NULL"
+	for _, reference := range codeData.References {
+		articleNumber := reference.ParentArticleID
+		if reference.NodeType == "ARTICLE" {
+			articleNumber = reference.NodeNumber
+		}
+		articleID := sqlVar(articleIDMap, sqlKey(reference.ParentPartID, reference.ParentSectionID, reference.ParentChapterID, reference.ParentParagraphID, articleNumber))
+
+		// Only references resolved in this act point at a row; the rest keep
+		// the cited number and act name.
+		targetArticleID := "NULL"
+		if reference.Resolved {
+			targetNumber := reference.TargetArticleID
+			if reference.TargetType == "ARTICLE" {
+				targetNumber = reference.TargetNumber
+			}
+			targetKey := sqlKey(reference.TargetPartID, reference.TargetSectionID, reference.TargetChapterID, reference.TargetParagraphID, targetNumber)
+			if id := articleIDMap[targetKey]; id != "" {
+				targetArticleID = id
+			}
 		}
 
-		sql += fmt.Sprintf("INSERT INTO SubClauses (ClauseID, Number, NameRu, NameKz) VALUES (%s, '%s', '%s', '%s');\n",
-			clauseID, subClause.Number, escapeSQLString(subClause.NameRu), escapeSQLString(subClause.NameKz))
+		sql += fmt.Sprintf("INSERT INTO [References] (CodeID, ArticleID, TargetArticleID, NodeType, NodeNumber, TargetType, TargetNumber, TargetPointNumber, TargetClauseNumber, External, Text) VALUES (@CodeID, %s, %s, '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s');\n",
+			articleID, targetArticleID, escapeSQLString(reference.NodeType), escapeSQLString(reference.NodeNumber), escapeSQLString(reference.TargetType), escapeSQLString(reference.TargetNumber),
+			escapeSQLString(reference.TargetPointID), escapeSQLString(reference.TargetClauseID), escapeSQLString(reference.External), escapeSQLString(reference.Text))
+	}
+
+	for _, term := range codeData.Glossary {
+		articleID := sqlVar(articleIDMap, sqlKey(term.ParentPartID, term.ParentSectionID, term.ParentChapterID, term.ParentParagraphID, term.ParentArticleID))
+
+		sql += fmt.Sprintf("INSERT INTO Glossary (CodeID, ArticleID, PointNumber, NodeType, NodeNumber, Language, Term, Definition) VALUES (@CodeID, %s, '%s', '%s', '%s', '%s', '%s', '%s');\n",
+			articleID, escapeSQLString(term.ParentPointID), escapeSQLString(term.NodeType), escapeSQLString(term.NodeNumber),
+			escapeSQLString(term.Language), escapeSQLString(term.Term), escapeSQLString(term.Definition))
 	}
 
 	_, err = file.WriteString(sql)
@@ -374,6 +745,40 @@ DECLARE @CodeID INT = SCOPE_IDENTITY();
 	return sqlPath, nil
 }
 
+// sqlKey joins the designations of a node's ancestors, from the part down,
+// into the key of its SQL variable. Levels the node skips are empty.
+func sqlKey(ids ...string) string {
+	return strings.Join(ids, "_")
+}
+
+// sqlVar returns the variable holding the row of a node, or NULL when the
+// node has no such ancestor.
+func sqlVar(idMap map[string]string, key string) string {
+	if id, ok := idMap[key]; ok {
+		return id
+	}
+	return "NULL"
+}
+
+func sqlDate(date string) string {
+	if date == "" {
+		return "NULL"
+	}
+	return "'" + escapeSQLString(date) + "'"
+}
+
 func escapeSQLString(s string) string {
 	return strings.ReplaceAll(s, "'", "''")
 }
+
+// sourceDebug renders where a node came from for the workbook's debug column.
+func sourceDebug(pos models.SourcePosition) string {
+	location := fmt.Sprintf("line %d, offset %d", pos.Line, pos.Offset)
+	if pos.Page > 0 {
+		location += fmt.Sprintf(", page %d", pos.Page)
+	}
+	if pos.Anchor != "" {
+		location += ", #" + pos.Anchor
+	}
+	return fmt.Sprintf("%s [%s] %s", location, pos.Pattern, pos.Heading)
+}
@@ -0,0 +1,53 @@
+package filehandler
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+	"github.com/DonBigBon/parser-backend/internal/parser"
+)
+
+func TestSQLDumpLinksNodesThatSkipLevels(t *testing.T) {
+	text := `ЧАСТЬ 1. ОБЩАЯ ЧАСТЬ
+Статья 1. Статья без главы
+1) подпункт без пункта;
+а) подподпункт;
+Глава 1. Глава без раздела
+Статья 2. Статья в главе
+1. Пункт.`
+	var lines []parser.Line
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, parser.Line{Text: line})
+	}
+	doc, err := parser.Parse(context.Background(), &parser.Source{Lines: lines}, parser.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(t.TempDir())
+	path, err := GenerateSQLDump(doc.Data, models.ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"INSERT INTO Articles (ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (NULL, NULL, NULL, @PartID_1, '1',",
+		"INSERT INTO Clauses (ArticleID, PointID, ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (@ArticleID_3, NULL, NULL, NULL, NULL, @PartID_1, '1',",
+		"INSERT INTO SubClauses (ClauseID, PointID, ArticleID, ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (@ClauseID_6, NULL, @ArticleID_3, NULL, NULL, NULL, @PartID_1, 'а',",
+		"INSERT INTO Chapters (SectionID, PartID, Number, SortKey, NameRu, NameKz, Status, EffectiveDate) VALUES (NULL, @PartID_1, '1',",
+		"INSERT INTO Articles (ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (NULL, @ChapterID_2, NULL, @PartID_1, '2',",
+		"INSERT INTO Points (ArticleID, ParagraphID, ChapterID, SectionID, PartID, Number, SortKey, NameRu, NameKz, TextRu, TextKz, Status, EffectiveDate) VALUES (@ArticleID_4, NULL, @ChapterID_2, NULL, @PartID_1, '1',",
+	}
+	for _, insert := range want {
+		if !strings.Contains(string(dump), insert) {
+			t.Errorf("dump lacks %q:\n%s", insert, dump)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+// ExportOptions controls what the workbook and SQL exporters write out.
+type ExportOptions struct {
+	// IncludeInactive keeps repealed and suspended provisions, marked with
+	// their status, instead of dropping them.
+	IncludeInactive bool
+}
+
+// ActiveOnly returns a copy of the data without provisions that are not in
+// force, together with everything nested in them and the notes, references
+// and glossary terms found in them.
+func (d CodeData) ActiveOnly() CodeData {
+	exportData{&d.Parts, &d.Sections, &d.Chapters, &d.Paragraphs, &d.Articles, &d.Points, &d.Clauses, &d.SubClauses,
+		&d.Notes, &d.References, &d.Glossary}.activeOnly()
+	return d
+}
+
+func (d ParsedData) ActiveOnly() ParsedData {
+	exportData{&d.Parts, &d.Sections, &d.Chapters, &d.Paragraphs, &d.Articles, &d.Points, &d.Clauses, &d.SubClauses,
+		&d.Notes, &d.References, &d.Glossary}.activeOnly()
+	return d
+}
+
+// exportData points at the slices of CodeData or ParsedData that ActiveOnly
+// filters.
+type exportData struct {
+	parts      *[]Part
+	sections   *[]Section
+	chapters   *[]Chapter
+	paragraphs *[]Paragraph
+	articles   *[]Article
+	points     *[]Point
+	clauses    *[]Clause
+	subClauses *[]SubClause
+	notes      *[]Note
+	references *[]Reference
+	glossary   *[]Term
+}
+
+func (e exportData) activeOnly() {
+	*e.parts = activeOnly(*e.parts, func(x Part) bool { return x.Active })
+	*e.sections = activeOnly(*e.sections, func(x Section) bool { return x.Active })
+	*e.chapters = activeOnly(*e.chapters, func(x Chapter) bool { return x.Active })
+	*e.paragraphs = activeOnly(*e.paragraphs, func(x Paragraph) bool { return x.Active })
+	*e.articles = activeOnly(*e.articles, func(x Article) bool { return x.Active })
+	*e.points = activeOnly(*e.points, func(x Point) bool { return x.Active })
+	*e.clauses = activeOnly(*e.clauses, func(x Clause) bool { return x.Active })
+	*e.subClauses = activeOnly(*e.subClauses, func(x SubClause) bool { return x.Active })
+	*e.notes = activeOnly(*e.notes, func(x Note) bool { return x.Active })
+	*e.references = activeOnly(*e.references, func(x Reference) bool { return x.Active })
+	*e.glossary = activeOnly(*e.glossary, func(x Term) bool { return x.Active })
+}
+
+func activeOnly[T any](items []T, active func(T) bool) []T {
+	var kept []T
+	for _, item := range items {
+		if active(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -1,86 +1,178 @@
 package models
 
+// Status tells whether a provision is in force.
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusRepealed  Status = "repealed"
+	StatusSuspended Status = "suspended"
+)
+
 type CodeData struct {
-	Parts      []Part
-	Sections   []Section
-	Chapters   []Chapter
-	Paragraphs []Paragraph
-	Articles   []Article
-	Clauses    []Clause
-	SubClauses []SubClause
+	Document   Document              `json:"document"`
+	TOC        []TOCEntry            `json:"toc"`
+	Parts      []Part                `json:"parts"`
+	Sections   []Section             `json:"sections"`
+	Chapters   []Chapter             `json:"chapters"`
+	Paragraphs []Paragraph           `json:"paragraphs"`
+	Articles   []Article             `json:"articles"`
+	Points     []Point               `json:"points"`
+	Clauses    []Clause              `json:"clauses"`
+	SubClauses []SubClause           `json:"subClauses"`
+	Notes      []Note                `json:"notes"`
+	References []Reference           `json:"references"`
+	Glossary   []Term                `json:"glossary"`
+	Normalized []NormalizationChange `json:"normalized"`
+	Warnings   []Warning             `json:"-"`
 }
 
 type Part struct {
-	ID     int    `json:"id"`
-	NameRu string `json:"nameRu"`
-	NameKz string `json:"nameKz"`
+	ID            string         `json:"id"`
+	SortKey       string         `json:"sortKey"`
+	NameRu        string         `json:"nameRu"`
+	NameKz        string         `json:"nameKz"`
+	Status        Status         `json:"status"`
+	EffectiveDate string         `json:"effectiveDate,omitempty"`
+	Active        bool           `json:"active"`
+	Source        SourcePosition `json:"source"`
 }
 
 type Section struct {
-	ID           int    `json:"id"`
-	ParentPartID int    `json:"parentPartId"`
-	NameRu       string `json:"nameRu"`
-	NameKz       string `json:"nameKz"`
+	ID            string         `json:"id"`
+	SortKey       string         `json:"sortKey"`
+	ParentType    string         `json:"parentType"`
+	ParentPartID  string         `json:"parentPartId"`
+	NameRu        string         `json:"nameRu"`
+	NameKz        string         `json:"nameKz"`
+	Status        Status         `json:"status"`
+	EffectiveDate string         `json:"effectiveDate,omitempty"`
+	Active        bool           `json:"active"`
+	Source        SourcePosition `json:"source"`
 }
 
 type Chapter struct {
-	ID              int    `json:"id"`
-	ParentSectionID int    `json:"parentSectionId"`
-	ParentPartID    int    `json:"parentPartId"`
-	NameRu          string `json:"nameRu"`
-	NameKz          string `json:"nameKz"`
+	ID              string         `json:"id"`
+	SortKey         string         `json:"sortKey"`
+	ParentType      string         `json:"parentType"`
+	ParentSectionID string         `json:"parentSectionId"`
+	ParentPartID    string         `json:"parentPartId"`
+	NameRu          string         `json:"nameRu"`
+	NameKz          string         `json:"nameKz"`
+	Status          Status         `json:"status"`
+	EffectiveDate   string         `json:"effectiveDate,omitempty"`
+	Active          bool           `json:"active"`
+	Source          SourcePosition `json:"source"`
 }
 
 type Paragraph struct {
-	ID              int    `json:"id"`
-	ParentChapterID int    `json:"parentChapterId"`
-	ParentSectionID int    `json:"parentSectionId"`
-	ParentPartID    int    `json:"parentPartId"`
-	NameRu          string `json:"nameRu"`
-	NameKz          string `json:"nameKz"`
+	ID              string         `json:"id"`
+	SortKey         string         `json:"sortKey"`
+	ParentType      string         `json:"parentType"`
+	ParentChapterID string         `json:"parentChapterId"`
+	ParentSectionID string         `json:"parentSectionId"`
+	ParentPartID    string         `json:"parentPartId"`
+	NameRu          string         `json:"nameRu"`
+	NameKz          string         `json:"nameKz"`
+	Status          Status         `json:"status"`
+	EffectiveDate   string         `json:"effectiveDate,omitempty"`
+	Active          bool           `json:"active"`
+	Source          SourcePosition `json:"source"`
 }
 
 type Article struct {
-	ID                int    `json:"id"`
-	ParentParagraphID int    `json:"parentParagraphId"`
-	ParentChapterID   int    `json:"parentChapterId"`
-	ParentSectionID   int    `json:"parentSectionId"`
-	ParentPartID      int    `json:"parentPartId"`
-	NameRu            string `json:"nameRu"`
-	NameKz            string `json:"nameKz"`
+	ID                string         `json:"id"`
+	SortKey           string         `json:"sortKey"`
+	ParentType        string         `json:"parentType"`
+	ParentParagraphID string         `json:"parentParagraphId"`
+	ParentChapterID   string         `json:"parentChapterId"`
+	ParentSectionID   string         `json:"parentSectionId"`
+	ParentPartID      string         `json:"parentPartId"`
+	NameRu            string         `json:"nameRu"`
+	NameKz            string         `json:"nameKz"`
+	Status            Status         `json:"status"`
+	EffectiveDate     string         `json:"effectiveDate,omitempty"`
+	Active            bool           `json:"active"`
+	Source            SourcePosition `json:"source"`
+	TextRu            string         `json:"textRu"`
+	TextKz            string         `json:"textKz"`
+}
+
+type Point struct {
+	ID                string         `json:"id"`
+	SortKey           string         `json:"sortKey"`
+	ParentType        string         `json:"parentType"`
+	ParentArticleID   string         `json:"parentArticleId"`
+	ParentParagraphID string         `json:"parentParagraphId"`
+	ParentChapterID   string         `json:"parentChapterId"`
+	ParentSectionID   string         `json:"parentSectionId"`
+	ParentPartID      string         `json:"parentPartId"`
+	NameRu            string         `json:"nameRu"`
+	NameKz            string         `json:"nameKz"`
+	Status            Status         `json:"status"`
+	EffectiveDate     string         `json:"effectiveDate,omitempty"`
+	Active            bool           `json:"active"`
+	Source            SourcePosition `json:"source"`
+	TextRu            string         `json:"textRu"`
+	TextKz            string         `json:"textKz"`
 }
 
 type Clause struct {
-	ID                int    `json:"id"`
-	ParentArticleID   int    `json:"parentArticleId"`
-	ParentParagraphID int    `json:"parentParagraphId"`
-	ParentChapterID   int    `json:"parentChapterId"`
-	ParentSectionID   int    `json:"parentSectionId"`
-	ParentPartID      int    `json:"parentPartId"`
-	NameRu            string `json:"nameRu"`
-	NameKz            string `json:"nameKz"`
+	ID                string         `json:"id"`
+	SortKey           string         `json:"sortKey"`
+	ParentType        string         `json:"parentType"`
+	ParentPointID     string         `json:"parentPointId"`
+	ParentArticleID   string         `json:"parentArticleId"`
+	ParentParagraphID string         `json:"parentParagraphId"`
+	ParentChapterID   string         `json:"parentChapterId"`
+	ParentSectionID   string         `json:"parentSectionId"`
+	ParentPartID      string         `json:"parentPartId"`
+	NameRu            string         `json:"nameRu"`
+	NameKz            string         `json:"nameKz"`
+	Status            Status         `json:"status"`
+	EffectiveDate     string         `json:"effectiveDate,omitempty"`
+	Active            bool           `json:"active"`
+	Source            SourcePosition `json:"source"`
+	TextRu            string         `json:"textRu"`
+	TextKz            string         `json:"textKz"`
 }
 
 type SubClause struct {
-	ID                int    `json:"id"`
-	ParentClauseID    int    `json:"parentClauseId"`
-	ParentArticleID   int    `json:"parentArticleId"`
-	ParentParagraphID int    `json:"parentParagraphId"`
-	ParentChapterID   int    `json:"parentChapterId"`
-	ParentSectionID   int    `json:"parentSectionId"`
-	ParentPartID      int    `json:"parentPartId"`
-	NameRu            string `json:"nameRu"`
-	NameKz            string `json:"nameKz"`
+	ID                string         `json:"id"`
+	SortKey           string         `json:"sortKey"`
+	ParentType        string         `json:"parentType"`
+	ParentClauseID    string         `json:"parentClauseId"`
+	ParentPointID     string         `json:"parentPointId"`
+	ParentArticleID   string         `json:"parentArticleId"`
+	ParentParagraphID string         `json:"parentParagraphId"`
+	ParentChapterID   string         `json:"parentChapterId"`
+	ParentSectionID   string         `json:"parentSectionId"`
+	ParentPartID      string         `json:"parentPartId"`
+	NameRu            string         `json:"nameRu"`
+	NameKz            string         `json:"nameKz"`
+	Status            Status         `json:"status"`
+	EffectiveDate     string         `json:"effectiveDate,omitempty"`
+	Active            bool           `json:"active"`
+	Source            SourcePosition `json:"source"`
+	TextRu            string         `json:"textRu"`
+	TextKz            string         `json:"textKz"`
 }
 
 type ParsedData struct {
-	Parts      []Part      `json:"parts"`
-	Sections   []Section   `json:"sections"`
-	Chapters   []Chapter   `json:"chapters"`
-	Paragraphs []Paragraph `json:"paragraphs"`
-	Articles   []Article   `json:"articles"`
-	Clauses    []Clause    `json:"clauses"`
-	SubClauses []SubClause `json:"subClauses"`
+	Document   Document              `json:"document"`
+	TOC        []TOCEntry            `json:"toc"`
+	Parts      []Part                `json:"parts"`
+	Sections   []Section             `json:"sections"`
+	Chapters   []Chapter             `json:"chapters"`
+	Paragraphs []Paragraph           `json:"paragraphs"`
+	Articles   []Article             `json:"articles"`
+	Points     []Point               `json:"points"`
+	Clauses    []Clause              `json:"clauses"`
+	SubClauses []SubClause           `json:"subClauses"`
+	Notes      []Note                `json:"notes"`
+	References []Reference           `json:"references"`
+	Glossary   []Term                `json:"glossary"`
+	Normalized []NormalizationChange `json:"normalized"`
 }
 
 type DocumentResult struct {
@@ -88,3 +180,136 @@ type DocumentResult struct {
 	SQLQueries []string          `json:"sqlQueries"`
 	CSVFiles   map[string]string `json:"csvFiles"`
 }
+
+// Document describes the act as a whole, as its preamble states it:
+// "Кодекс Республики Казахстан от 25 декабря 2017 года № 120-VI ЗРК «О
+// налогах и других обязательных платежах в бюджет»". Date is ISO 8601;
+// Language is "ru" or "kz".
+type Document struct {
+	ActType  string `json:"actType"`
+	Date     string `json:"date"`
+	Number   string `json:"number"`
+	Title    string `json:"title"`
+	Language string `json:"language"`
+}
+
+// TOCEntry is one line of a table of contents. The entries are kept apart
+// from the body so that the headings they repeat are not parsed twice. Page
+// is 0 when the entry gives none.
+type TOCEntry struct {
+	NodeType string `json:"nodeType"`
+	Number   string `json:"number"`
+	Title    string `json:"title"`
+	Page     int    `json:"page"`
+	Line     int    `json:"line"`
+}
+
+// NormalizationChange counts the lines one kind of normalization rewrote
+// before matching, e.g. "homoglyphs" for Latin letters in Cyrillic words.
+// FirstLine is the number of the first line changed.
+type NormalizationChange struct {
+	Kind      string `json:"kind"`
+	Count     int    `json:"count"`
+	FirstLine int    `json:"firstLine"`
+}
+
+// Note is one amending act cited by an editorial note ("Сноска"). The note
+// belongs to the node given by NodeType and NodeNumber; the Parent fields
+// place that node in the tree like on the other structs.
+type Note struct {
+	NodeType          string         `json:"nodeType"`
+	NodeNumber        string         `json:"nodeNumber"`
+	ParentArticleID   string         `json:"parentArticleId"`
+	ParentParagraphID string         `json:"parentParagraphId"`
+	ParentChapterID   string         `json:"parentChapterId"`
+	ParentSectionID   string         `json:"parentSectionId"`
+	ParentPartID      string         `json:"parentPartId"`
+	Change            string         `json:"change"`
+	ActType           string         `json:"actType"`
+	ActDate           string         `json:"actDate"`
+	ActNumber         string         `json:"actNumber"`
+	Text              string         `json:"text"`
+	Active            bool           `json:"active"`
+	Source            SourcePosition `json:"source"`
+}
+
+// Reference is a citation found in the text of a node. Internal references
+// name the provision they point to and are resolved against the parsed tree;
+// references to other acts keep the act's name in External.
+type Reference struct {
+	NodeType          string         `json:"nodeType"`
+	NodeNumber        string         `json:"nodeNumber"`
+	ParentArticleID   string         `json:"parentArticleId"`
+	ParentParagraphID string         `json:"parentParagraphId"`
+	ParentChapterID   string         `json:"parentChapterId"`
+	ParentSectionID   string         `json:"parentSectionId"`
+	ParentPartID      string         `json:"parentPartId"`
+	TargetType        string         `json:"targetType"`
+	TargetNumber      string         `json:"targetNumber"`
+	TargetClauseID    string         `json:"targetClauseId"`
+	TargetPointID     string         `json:"targetPointId"`
+	TargetArticleID   string         `json:"targetArticleId"`
+	TargetParagraphID string         `json:"targetParagraphId"`
+	TargetChapterID   string         `json:"targetChapterId"`
+	TargetSectionID   string         `json:"targetSectionId"`
+	TargetPartID      string         `json:"targetPartId"`
+	Resolved          bool           `json:"resolved"`
+	External          string         `json:"external,omitempty"`
+	Text              string         `json:"text"`
+	Active            bool           `json:"active"`
+	Source            SourcePosition `json:"source"`
+}
+
+// Term is a definition from an act's glossary article, "налогоплательщик –
+// лицо, ...", kept in the language it is written in.
+type Term struct {
+	NodeType          string         `json:"nodeType"`
+	NodeNumber        string         `json:"nodeNumber"`
+	ParentPointID     string         `json:"parentPointId"`
+	ParentArticleID   string         `json:"parentArticleId"`
+	ParentParagraphID string         `json:"parentParagraphId"`
+	ParentChapterID   string         `json:"parentChapterId"`
+	ParentSectionID   string         `json:"parentSectionId"`
+	ParentPartID      string         `json:"parentPartId"`
+	Language          string         `json:"language"`
+	Term              string         `json:"term"`
+	Definition        string         `json:"definition"`
+	Active            bool           `json:"active"`
+	Source            SourcePosition `json:"source"`
+}
+
+// CitedBy returns the internal references to an article or to any point or
+// clause inside it.
+func (d CodeData) CitedBy(articleID string) []Reference {
+	var refs []Reference
+	for _, ref := range d.References {
+		if !ref.Resolved {
+			continue
+		}
+		if (ref.TargetType == "ARTICLE" && ref.TargetNumber == articleID) || ref.TargetArticleID == articleID {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// SourcePosition tells where in the uploaded file a node was found and
+// which rule pattern recognised its heading.
+type SourcePosition struct {
+	Line    int    `json:"line"`
+	Offset  int    `json:"offset"`
+	Page    int    `json:"page,omitempty"`
+	Anchor  string `json:"anchor,omitempty"`
+	Heading string `json:"heading"`
+	Pattern string `json:"pattern"`
+}
+
+type Warning struct {
+	Line         int    `json:"line"`
+	Kind         string `json:"kind"`
+	NodeType     string `json:"nodeType"`
+	Number       string `json:"number"`
+	ParentType   string `json:"parentType"`
+	ParentNumber string `json:"parentNumber"`
+	Message      string `json:"message"`
+}
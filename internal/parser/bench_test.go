@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchmarkCode builds a synthetic code of about size bytes with the usual
+// mix of headings, points, clauses, body text and notes.
+func benchmarkCode(size int) string {
+	var b strings.Builder
+	for article := 1; b.Len() < size; article++ {
+		if article%500 == 1 {
+			fmt.Fprintf(&b, "РАЗДЕЛ %d. НАЛОГОВОЕ АДМИНИСТРИРОВАНИЕ\n", article/500+1)
+		}
+		if article%50 == 1 {
+			fmt.Fprintf(&b, "Глава %d. ОБЩИЕ ПОЛОЖЕНИЯ О НАЛОГАХ\nИ ДРУГИХ ОБЯЗАТЕЛЬНЫХ ПЛАТЕЖАХ\n", article/50+1)
+		}
+		fmt.Fprintf(&b, "Статья %d. Порядок исчисления и уплаты налога\n", article)
+		if article%7 == 0 {
+			fmt.Fprintf(&b, "Сноска. Статья %d с изменениями, внесенными Законом РК от 10.01.2018 № 133-VI.\n", article)
+		}
+		fmt.Fprintf(&b, "1. Налогоплательщики исчисляют налог в соответствии со статьей %d настоящего Кодекса.\n", article)
+		b.WriteString("Налог уплачивается в бюджет по месту нахождения налогоплательщика в сроки, установленные настоящим Кодексом.\n")
+		b.WriteString("2. Налоговый период определяется:\n1) для юридических лиц – календарный год;\n")
+		b.WriteString("а) для резидентов;\nб) для нерезидентов;\n2) для индивидуальных предпринимателей – квартал.\n")
+		b.WriteString("Положения настоящей статьи применяются с учетом особенностей, предусмотренных подпунктом 1) пункта 2 настоящей статьи.\n\n")
+	}
+	return b.String()
+}
+
+func BenchmarkParseDocument(b *testing.B) {
+	content := benchmarkCode(4 << 20)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewParser().ParseDocument(content)
+	}
+}
+
+func BenchmarkParseReader(b *testing.B) {
+	content := benchmarkCode(4 << 20)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := NewParser().ParseReader(strings.NewReader(content), func(*DocumentNode) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// sequentialParser returns a parser that tries every pattern of every level
+// in rule order on each line, as it did before lines were dispatched by
+// their first character. It is the baseline of the Sequential benchmarks.
+func sequentialParser() *Parser {
+	p := NewParser()
+	var all []candidate
+	for i, lvl := range p.levels {
+		for j := range lvl.patterns {
+			all = append(all, candidate{level: i, pattern: j})
+		}
+	}
+	p.classifier = &classifier{other: &dispatch{candidates: all}}
+	return p
+}
+
+func BenchmarkParseDocumentSequential(b *testing.B) {
+	content := benchmarkCode(4 << 20)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sequentialParser().ParseDocument(content)
+	}
+}
+
+func BenchmarkParseReaderSequential(b *testing.B) {
+	content := benchmarkCode(4 << 20)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := sequentialParser().ParseReader(strings.NewReader(content), func(*DocumentNode) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkLines() []string {
+	lines := strings.Split(benchmarkCode(4<<20), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
+
+func BenchmarkClassify(b *testing.B) {
+	p := NewParser()
+	lines := benchmarkLines()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			p.classify(line)
+		}
+	}
+}
+
+// BenchmarkClassifySequential runs every pattern in rule order, as the
+// parser did before lines were dispatched by their first character.
+func BenchmarkClassifySequential(b *testing.B) {
+	p := NewParser()
+	lines := benchmarkLines()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			classifySequential(p, line)
+		}
+	}
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	n := NewParser().normalization
+	lines := benchmarkLines()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			n.normalize(line)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// maxDispatchRunes caps how many first characters one pattern may list
+// before it is tried on every line instead.
+const maxDispatchRunes = 512
+
+// candidate is one pattern of one level, in rule order.
+type candidate struct {
+	level   int
+	pattern int
+}
+
+// classifier picks the patterns worth trying on a line by its first
+// character: "Статья 5" can only open an article, "5)" a clause or one of
+// the Kazakh headings that start with a number, and most body text matches
+// no pattern at all and is passed over without running a regex. The
+// patterns left for one character are joined into a single alternation, so
+// a line is read once whichever of them matches.
+type classifier struct {
+	byRune map[rune]*dispatch
+	other  *dispatch
+}
+
+// dispatch holds the candidates for one first character. combined is their
+// alternation, each alternative wrapped in a group that starts at the
+// matching index of groups; it is nil when a pattern cannot be combined and
+// the candidates are tried one by one.
+type dispatch struct {
+	candidates []candidate
+	combined   *regexp.Regexp
+	groups     []int
+}
+
+// heading is the result of classifying a line that opens a node.
+type heading struct {
+	level   int
+	pattern levelPattern
+	match   []string
+}
+
+func newClassifier(levels []level) *classifier {
+	var all []candidate
+	firsts := make(map[candidate]map[rune]bool)
+	for i, lvl := range levels {
+		for j, pattern := range lvl.patterns {
+			c := candidate{level: i, pattern: j}
+			all = append(all, c)
+			if runes, ok := patternFirstRunes(pattern.re.String()); ok {
+				firsts[c] = runes
+			}
+		}
+	}
+
+	// Patterns whose first character is unknown go into every list, so each
+	// list keeps the order of the rules.
+	lists := make(map[rune][]candidate)
+	for _, runes := range firsts {
+		for r := range runes {
+			lists[r] = nil
+		}
+	}
+	var other []candidate
+	for _, cand := range all {
+		runes, known := firsts[cand]
+		if !known {
+			other = append(other, cand)
+		}
+		for r := range lists {
+			if !known || runes[r] {
+				lists[r] = append(lists[r], cand)
+			}
+		}
+	}
+
+	// Characters with the same candidates share one dispatch.
+	c := &classifier{byRune: make(map[rune]*dispatch), other: newDispatch(levels, other)}
+	shared := make(map[string]*dispatch)
+	for r, list := range lists {
+		key := fmt.Sprint(list)
+		if shared[key] == nil {
+			shared[key] = newDispatch(levels, list)
+		}
+		c.byRune[r] = shared[key]
+	}
+	return c
+}
+
+func newDispatch(levels []level, candidates []candidate) *dispatch {
+	d := &dispatch{candidates: candidates}
+	if len(candidates) < 2 {
+		return d
+	}
+
+	alternatives := make([]string, 0, len(candidates))
+	group := 1
+	for _, cand := range candidates {
+		re := levels[cand.level].patterns[cand.pattern].re
+		body, ok := unanchored(re.String())
+		if !ok {
+			return d
+		}
+		alternatives = append(alternatives, "("+body+")")
+		d.groups = append(d.groups, group)
+		group += re.NumSubexp() + 1
+	}
+
+	combined, err := regexp.Compile(`^(?:` + strings.Join(alternatives, "|") + `)`)
+	if err != nil {
+		return &dispatch{candidates: candidates}
+	}
+	d.combined = combined
+	return d
+}
+
+// unanchored returns an anchored pattern without its leading ^, so that
+// several patterns can share one anchor.
+func unanchored(expr string) (string, bool) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil || re.Op != syntax.OpConcat || len(re.Sub) == 0 || re.Sub[0].Op != syntax.OpBeginText {
+		return "", false
+	}
+	re.Sub = re.Sub[1:]
+	return re.String(), true
+}
+
+func (c *classifier) dispatch(text string) *dispatch {
+	for _, r := range text {
+		if d, ok := c.byRune[r]; ok {
+			return d
+		}
+		return c.other
+	}
+	return nil
+}
+
+// classify finds the first level, in rule order, whose pattern matches the
+// line.
+func (p *Parser) classify(text string) (heading, bool) {
+	d := p.classifier.dispatch(text)
+	if d == nil {
+		return heading{}, false
+	}
+
+	if d.combined == nil {
+		for _, cand := range d.candidates {
+			pattern := p.levels[cand.level].patterns[cand.pattern]
+			if match := pattern.re.FindStringSubmatch(text); match != nil {
+				return heading{level: cand.level, pattern: pattern, match: match}, true
+			}
+		}
+		return heading{}, false
+	}
+
+	loc := d.combined.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return heading{}, false
+	}
+	for i, cand := range d.candidates {
+		group := d.groups[i]
+		if loc[2*group] < 0 {
+			continue
+		}
+		pattern := p.levels[cand.level].patterns[cand.pattern]
+		match := make([]string, pattern.re.NumSubexp()+1)
+		for k := range match {
+			if start, end := loc[2*(group+k)], loc[2*(group+k)+1]; start >= 0 {
+				match[k] = text[start:end]
+			}
+		}
+		return heading{level: cand.level, pattern: pattern, match: match}, true
+	}
+	return heading{}, false
+}
+
+// patternFirstRunes works out which characters a line must start with for
+// an anchored pattern to match it. It reports false when the pattern is not
+// anchored at the start or may begin with too many different characters.
+func patternFirstRunes(expr string) (map[rune]bool, bool) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	if re.Op != syntax.OpConcat || len(re.Sub) == 0 ||
+		(re.Sub[0].Op != syntax.OpBeginText && re.Sub[0].Op != syntax.OpBeginLine) {
+		return nil, false
+	}
+
+	runes := make(map[rune]bool)
+	nullable, ok := firstRunes(re, runes)
+	if !ok || nullable || len(runes) > maxDispatchRunes {
+		return nil, false
+	}
+	return runes, true
+}
+
+// firstRunes adds the characters re can start with to runes and reports
+// whether re can match the empty string.
+func firstRunes(re *syntax.Regexp, runes map[rune]bool) (nullable bool, ok bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true, true
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return true, true
+		}
+		addRune(runes, re.Rune[0], re.Flags&syntax.FoldCase != 0)
+		return false, true
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i+1]-re.Rune[i] > maxDispatchRunes {
+				return false, false
+			}
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				runes[r] = true
+			}
+		}
+		return false, len(runes) <= maxDispatchRunes
+	case syntax.OpCapture:
+		return firstRunes(re.Sub[0], runes)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			subNullable, ok := firstRunes(sub, runes)
+			if !ok {
+				return false, false
+			}
+			if !subNullable {
+				return false, true
+			}
+		}
+		return true, true
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			subNullable, ok := firstRunes(sub, runes)
+			if !ok {
+				return false, false
+			}
+			nullable = nullable || subNullable
+		}
+		return nullable, true
+	case syntax.OpStar, syntax.OpQuest:
+		_, ok := firstRunes(re.Sub[0], runes)
+		return true, ok
+	case syntax.OpPlus:
+		return firstRunes(re.Sub[0], runes)
+	case syntax.OpRepeat:
+		subNullable, ok := firstRunes(re.Sub[0], runes)
+		return subNullable || re.Min == 0, ok
+	}
+	return false, false
+}
+
+func addRune(runes map[rune]bool, r rune, fold bool) {
+	runes[r] = true
+	if !fold {
+		return
+	}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		runes[f] = true
+	}
+}
+
+// runeFilter tells cheaply whether a line can match any of a few anchored
+// patterns before the patterns themselves run. A nil filter lets every line
+// through.
+type runeFilter map[rune]bool
+
+func newRuneFilter(res ...*regexp.Regexp) runeFilter {
+	filter := make(runeFilter)
+	for _, re := range res {
+		runes, ok := patternFirstRunes(re.String())
+		if !ok {
+			return nil
+		}
+		for r := range runes {
+			filter[r] = true
+		}
+	}
+	return filter
+}
+
+func (f runeFilter) mayMatch(text string) bool {
+	if f == nil {
+		return true
+	}
+	for _, r := range text {
+		return f[r]
+	}
+	return false
+}
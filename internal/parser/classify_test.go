@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// classifySequential is the plain reading of the rules: every pattern of
+// every level in order until one matches.
+func classifySequential(p *Parser, text string) (heading, bool) {
+	for i, lvl := range p.levels {
+		for _, pattern := range lvl.patterns {
+			if match := pattern.re.FindStringSubmatch(text); match != nil {
+				return heading{level: i, pattern: pattern, match: match}, true
+			}
+		}
+	}
+	return heading{}, false
+}
+
+func TestClassifyMatchesRuleOrder(t *testing.T) {
+	lines := strings.Split(testCode+"\n"+benchmarkCode(64<<10), "\n")
+	lines = append(lines,
+		"1-БӨЛІК. ЖАЛПЫ БӨЛІК", "БӨЛІМ II. ЖАЛПЫ ЕРЕЖЕЛЕР", "2-тарау. Салықтар", "бап 5. Салық агенттері",
+		"15-1-бап. Салық", "II. Общие положения", "ә) тармақша;", "c) clause", "1.1. Подпункт", "Приложение 1", "")
+
+	for _, profile := range Profiles() {
+		p, err := NewParserForProfile(profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			got, gotOK := p.classify(line)
+			want, wantOK := classifySequential(p, line)
+			if gotOK != wantOK || got.level != want.level || got.pattern.name != want.pattern.name || !reflect.DeepEqual(got.match, want.match) {
+				t.Errorf("%s: %q classified as %v %s %q, want %v %s %q",
+					profile, line, gotOK, got.pattern.name, got.match, wantOK, want.pattern.name, want.match)
+			}
+		}
+	}
+}
+
+func TestClassifyKazakhHeadings(t *testing.T) {
+	tests := []struct {
+		text     string
+		nodeType string
+		number   string
+		name     string
+	}{
+		{"1-БӨЛІК. ЖАЛПЫ БӨЛІК", "PART", "1", "ЖАЛПЫ БӨЛІК"},
+		{"2-бөлім. Салықтық әкімшілендіру", "SECTION", "2", "Салықтық әкімшілендіру"},
+		{"БӨЛІМ II. ЖАЛПЫ ЕРЕЖЕЛЕР", "SECTION", "II", "ЖАЛПЫ ЕРЕЖЕЛЕР"},
+		{"3-тарау. Салықтар", "CHAPTER", "3", "Салықтар"},
+		{"ТАРАУ 4. НЕГІЗГІ ЕРЕЖЕЛЕР", "CHAPTER", "4", "НЕГІЗГІ ЕРЕЖЕЛЕР"},
+		{"5-бап. Салық агенттері", "ARTICLE", "5", "Салық агенттері"},
+		{"65-1-бап. Салық кезеңі", "ARTICLE", "65-1", "Салық кезеңі"},
+		{"12-2 - бап. Қағидалар", "ARTICLE", "12-2", "Қағидалар"},
+		{"Бап 6. Салық төлеушілер", "ARTICLE", "6", "Салық төлеушілер"},
+		{"1. Осы Кодекс салықтық қатынастарды реттейді.", "POINT", "1", "Осы Кодекс салықтық қатынастарды реттейді."},
+		{"2) салық төлеушілер;", "CLAUSE", "2", "салық төлеушілер;"},
+		{"ә) резиденттер;", "SUBCLAUSE", "ә", "резиденттер;"},
+		{"1-тармақта көзделген жағдайларда", "", "", ""},
+		{"бап бойынша", "", "", ""},
+	}
+
+	p := NewParser()
+	for _, tt := range tests {
+		h, ok := p.classify(tt.text)
+		if tt.nodeType == "" {
+			if ok {
+				t.Errorf("%q classified as %s", tt.text, p.levels[h.level].nodeType)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%q not classified, want %s", tt.text, tt.nodeType)
+			continue
+		}
+		if got := p.levels[h.level].nodeType; got != tt.nodeType || strings.TrimSpace(h.match[1]) != tt.number || h.match[2] != tt.name {
+			t.Errorf("%q: got %s %q %q, want %s %q %q", tt.text, got, h.match[1], h.match[2], tt.nodeType, tt.number, tt.name)
+		}
+	}
+}
+
+func TestSequentialParserMatchesParser(t *testing.T) {
+	content := testCode + "\n" + benchmarkCode(64<<10)
+	p := NewParser()
+	p.ParseDocument(content)
+	want := p.ConvertToFlatData()
+
+	seq := sequentialParser()
+	seq.ParseDocument(content)
+	got := seq.ConvertToFlatData()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sequential parse differs: %d articles, %d clauses; want %d, %d",
+			len(got.Articles), len(got.Clauses), len(want.Articles), len(want.Clauses))
+	}
+}
@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+const (
+	docFibWhichTblStm = 0x0200
+	docFibEncrypted   = 0x0100
+	docMinFibVersion  = 0x00C1
+	docFcClxIndex     = 33
+	docCcpTextIndex   = 3
+	docFcCompressed   = 0x40000000
+)
+
+func readDOC(filePath string) (*Source, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening doc: %v", err)
+	}
+	defer file.Close()
+
+	compound, err := mscfb.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading doc: %v", err)
+	}
+
+	streams := make(map[string][]byte)
+	for entry, err := compound.Next(); err == nil; entry, err = compound.Next() {
+		switch entry.Name {
+		case "WordDocument", "0Table", "1Table":
+			data, err := io.ReadAll(entry)
+			if err != nil {
+				return nil, fmt.Errorf("error reading doc stream %s: %v", entry.Name, err)
+			}
+			streams[entry.Name] = data
+		}
+	}
+
+	wordDocument := streams["WordDocument"]
+	if len(wordDocument) < 34 {
+		return nil, fmt.Errorf("error reading doc: WordDocument stream not found")
+	}
+
+	text, err := readDOCText(wordDocument, streams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{Lines: splitDOCText(text)}, nil
+}
+
+// readDOCText reconstructs the main document text from the piece table
+// stored in the Clx structure of the table stream.
+func readDOCText(wordDocument []byte, streams map[string][]byte) (string, error) {
+	nFib := binary.LittleEndian.Uint16(wordDocument[2:])
+	flags := binary.LittleEndian.Uint16(wordDocument[10:])
+	if nFib < docMinFibVersion {
+		return "", fmt.Errorf("error reading doc: Word 6/95 files are not supported")
+	}
+	if flags&docFibEncrypted != 0 {
+		return "", fmt.Errorf("error reading doc: document is encrypted")
+	}
+
+	tableName := "0Table"
+	if flags&docFibWhichTblStm != 0 {
+		tableName = "1Table"
+	}
+	table, ok := streams[tableName]
+	if !ok {
+		return "", fmt.Errorf("error reading doc: %s stream not found", tableName)
+	}
+
+	offset := 32
+	csw := int(binary.LittleEndian.Uint16(wordDocument[offset:]))
+	offset += 2 + csw*2
+	if offset+2 > len(wordDocument) {
+		return "", fmt.Errorf("error reading doc: truncated FIB")
+	}
+	cslw := int(binary.LittleEndian.Uint16(wordDocument[offset:]))
+	lwOffset := offset + 2
+	offset = lwOffset + cslw*4
+	if offset+2 > len(wordDocument) {
+		return "", fmt.Errorf("error reading doc: truncated FIB")
+	}
+	fcLcbOffset := offset + 2
+	clxOffset := fcLcbOffset + docFcClxIndex*8
+	if clxOffset+8 > len(wordDocument) {
+		return "", fmt.Errorf("error reading doc: truncated FIB")
+	}
+
+	ccpText := int(binary.LittleEndian.Uint32(wordDocument[lwOffset+docCcpTextIndex*4:]))
+	fcClx := int(binary.LittleEndian.Uint32(wordDocument[clxOffset:]))
+	lcbClx := int(binary.LittleEndian.Uint32(wordDocument[clxOffset+4:]))
+	if fcClx+lcbClx > len(table) {
+		return "", fmt.Errorf("error reading doc: Clx outside of table stream")
+	}
+
+	pieces, err := readDOCPieceTable(table[fcClx : fcClx+lcbClx])
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	remaining := ccpText
+	for _, piece := range pieces {
+		if remaining <= 0 {
+			break
+		}
+		// Both ends come from the file; check the span before sizing a
+		// buffer with it.
+		chars := piece.cpEnd - piece.cpStart
+		if chars < 0 || piece.fc > len(wordDocument) {
+			return "", fmt.Errorf("error reading doc: piece outside of WordDocument stream")
+		}
+		if chars > remaining {
+			chars = remaining
+		}
+		remaining -= chars
+
+		if piece.compressed {
+			end := piece.fc + chars
+			if end > len(wordDocument) {
+				return "", fmt.Errorf("error reading doc: piece outside of WordDocument stream")
+			}
+			text.WriteString(decodeCodepage(wordDocument[piece.fc:end], 1252))
+			continue
+		}
+
+		end := piece.fc + chars*2
+		if end > len(wordDocument) {
+			return "", fmt.Errorf("error reading doc: piece outside of WordDocument stream")
+		}
+		units := make([]uint16, chars)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(wordDocument[piece.fc+i*2:])
+		}
+		text.WriteString(string(utf16.Decode(units)))
+	}
+
+	return text.String(), nil
+}
+
+type docPiece struct {
+	cpStart    int
+	cpEnd      int
+	fc         int
+	compressed bool
+}
+
+func readDOCPieceTable(clx []byte) ([]docPiece, error) {
+	pos := 0
+	for pos < len(clx) && clx[pos] == 0x01 {
+		if pos+3 > len(clx) {
+			return nil, fmt.Errorf("error reading doc: truncated Clx")
+		}
+		cbGrpprl := int(binary.LittleEndian.Uint16(clx[pos+1:]))
+		pos += 3 + cbGrpprl
+	}
+	if pos+5 > len(clx) || clx[pos] != 0x02 {
+		return nil, fmt.Errorf("error reading doc: piece table not found")
+	}
+
+	lcb := int(binary.LittleEndian.Uint32(clx[pos+1:]))
+	plcPcd := clx[pos+5:]
+	if lcb > len(plcPcd) || lcb < 4 {
+		return nil, fmt.Errorf("error reading doc: truncated piece table")
+	}
+
+	count := (lcb - 4) / 12
+	pieces := make([]docPiece, 0, count)
+	for i := 0; i < count; i++ {
+		cpStart := int(binary.LittleEndian.Uint32(plcPcd[i*4:]))
+		cpEnd := int(binary.LittleEndian.Uint32(plcPcd[(i+1)*4:]))
+		if cpEnd < cpStart {
+			return nil, fmt.Errorf("error reading doc: piece %d ends before it starts", i)
+		}
+		pcd := plcPcd[(count+1)*4+i*8:]
+		fc := binary.LittleEndian.Uint32(pcd[2:])
+
+		piece := docPiece{cpStart: cpStart, cpEnd: cpEnd, fc: int(fc)}
+		if fc&docFcCompressed != 0 {
+			piece.compressed = true
+			piece.fc = int(fc&^docFcCompressed) / 2
+		}
+		pieces = append(pieces, piece)
+	}
+
+	return pieces, nil
+}
+
+// splitDOCText turns Word's special characters into plain lines. Field
+// instructions (between 0x13 and 0x14) are dropped and only the field
+// result is kept.
+func splitDOCText(text string) []Line {
+	var lines []Line
+	var line strings.Builder
+	var fields []bool
+
+	for _, ch := range text {
+		switch ch {
+		case 0x13:
+			fields = append(fields, true)
+			continue
+		case 0x14:
+			if len(fields) > 0 {
+				fields[len(fields)-1] = false
+			}
+			continue
+		case 0x15:
+			if len(fields) > 0 {
+				fields = fields[:len(fields)-1]
+			}
+			continue
+		}
+		if inFieldInstruction(fields) {
+			continue
+		}
+
+		switch ch {
+		case '\r', 0x0B, 0x0C, 0x07:
+			lines = append(lines, Line{Text: strings.TrimRight(line.String(), " \t")})
+			line.Reset()
+		case 0x1E:
+			line.WriteByte('-')
+		case 0x1F, 0x01, 0x08:
+		default:
+			line.WriteRune(ch)
+		}
+	}
+	if line.Len() > 0 {
+		lines = append(lines, Line{Text: strings.TrimRight(line.String(), " \t")})
+	}
+
+	return lines
+}
+
+func inFieldInstruction(fields []bool) bool {
+	for _, instruction := range fields {
+		if instruction {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// docClx builds a Clx holding one piece table entry.
+func docClx(cpStart, cpEnd, fc uint32) []byte {
+	plc := make([]byte, 8+8)
+	binary.LittleEndian.PutUint32(plc[0:], cpStart)
+	binary.LittleEndian.PutUint32(plc[4:], cpEnd)
+	binary.LittleEndian.PutUint32(plc[8+2:], fc)
+
+	clx := []byte{0x02, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(clx[1:], uint32(len(plc)))
+	return append(clx, plc...)
+}
+
+func TestReadDOCPieceTable(t *testing.T) {
+	pieces, err := readDOCPieceTable(docClx(0, 5, 0x40000000|200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) != 1 || !pieces[0].compressed || pieces[0].fc != 100 || pieces[0].cpEnd != 5 {
+		t.Errorf("pieces = %+v", pieces)
+	}
+
+	if _, err := readDOCPieceTable(docClx(10, 5, 0)); err == nil || !strings.Contains(err.Error(), "error reading doc") {
+		t.Errorf("inverted piece: err = %v", err)
+	}
+}
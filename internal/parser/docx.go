@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func readDOCX(filePath string) (*Source, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening docx: %v", err)
+	}
+	defer archive.Close()
+
+	var document, styles *zip.File
+	for _, f := range archive.File {
+		switch f.Name {
+		case "word/document.xml":
+			document = f
+		case "word/styles.xml":
+			styles = f
+		}
+	}
+	if document == nil {
+		return nil, fmt.Errorf("error reading docx: word/document.xml not found")
+	}
+
+	styleNames := make(map[string]string)
+	if styles != nil {
+		if styleNames, err = readDOCXStyles(styles); err != nil {
+			return nil, err
+		}
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading docx: %v", err)
+	}
+	defer rc.Close()
+
+	lines, err := readDOCXBody(rc, styleNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{Encoding: "utf-8", Lines: lines}, nil
+}
+
+// readDOCXStyles maps style IDs used by w:pStyle to their display names, so
+// that "a1" or "Heading2" shows up as the name the author picked in Word.
+func readDOCXStyles(f *zip.File) (map[string]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading docx styles: %v", err)
+	}
+	defer rc.Close()
+
+	names := make(map[string]string)
+	decoder := xml.NewDecoder(rc)
+	styleID := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading docx styles: %v", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "style":
+			styleID = xmlAttr(start, "styleId")
+		case "name":
+			if styleID != "" {
+				names[styleID] = xmlAttr(start, "val")
+			}
+		}
+	}
+
+	return names, nil
+}
+
+func readDOCXBody(r io.Reader, styleNames map[string]string) ([]Line, error) {
+	var lines []Line
+	var text strings.Builder
+	style := ""
+	inParagraph := false
+	inRun := false
+	inText := false
+
+	// A Word table of contents is a TOC field, whose entries are the
+	// field's result, often wrapped in a content control from the "Table of
+	// Contents" gallery. Fields nest: every entry holds a PAGEREF field.
+	var instr strings.Builder
+	inInstr := false
+	fieldDepth, tocDepth := 0, 0
+	var sdtTOC []bool
+	paragraphTOC := false
+	inTOC := func() bool {
+		if tocDepth > 0 {
+			return true
+		}
+		for _, toc := range sdtTOC {
+			if toc {
+				return true
+			}
+		}
+		return false
+	}
+
+	flush := func() {
+		lines = append(lines, Line{Text: strings.TrimRight(text.String(), " \t"), Style: style, TOC: paragraphTOC || inTOC()})
+		text.Reset()
+		paragraphTOC = inTOC()
+	}
+
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading docx: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				inParagraph = true
+				style = ""
+				text.Reset()
+				paragraphTOC = inTOC()
+			case "pStyle":
+				id := xmlAttr(t, "val")
+				style = id
+				if name, ok := styleNames[id]; ok && name != "" {
+					style = name
+				}
+			case "r":
+				inRun = true
+			case "t":
+				inText = true
+			case "tab":
+				// Tab stops in paragraph properties are w:tab too; only a
+				// tab inside a run is text.
+				if inParagraph && inRun {
+					text.WriteByte('\t')
+				}
+			case "br", "cr":
+				if inParagraph {
+					flush()
+				}
+			case "noBreakHyphen":
+				text.WriteByte('-')
+			case "sym":
+				text.WriteRune(docxSymbol(xmlAttr(t, "char")))
+			case "instrText":
+				inInstr = true
+			case "fldChar":
+				switch xmlAttr(t, "fldCharType") {
+				case "begin":
+					fieldDepth++
+					instr.Reset()
+				case "separate":
+					if tocDepth == 0 && isTOCField(instr.String()) {
+						tocDepth = fieldDepth
+						paragraphTOC = true
+					}
+				case "end":
+					if fieldDepth == tocDepth {
+						tocDepth = 0
+					}
+					if fieldDepth > 0 {
+						fieldDepth--
+					}
+				}
+			case "fldSimple":
+				fieldDepth++
+				if tocDepth == 0 && isTOCField(xmlAttr(t, "instr")) {
+					tocDepth = fieldDepth
+					paragraphTOC = true
+				}
+			case "sdt":
+				sdtTOC = append(sdtTOC, false)
+			case "docPartGallery":
+				if len(sdtTOC) > 0 && strings.Contains(xmlAttr(t, "val"), "Table of Contents") {
+					sdtTOC[len(sdtTOC)-1] = true
+					paragraphTOC = true
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p":
+				if inParagraph {
+					flush()
+				}
+				inParagraph = false
+			case "r":
+				inRun = false
+			case "t":
+				inText = false
+			case "instrText":
+				inInstr = false
+			case "fldSimple":
+				if fieldDepth == tocDepth {
+					tocDepth = 0
+				}
+				if fieldDepth > 0 {
+					fieldDepth--
+				}
+			case "sdt":
+				if len(sdtTOC) > 0 {
+					sdtTOC = sdtTOC[:len(sdtTOC)-1]
+				}
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+			if inInstr {
+				instr.Write(t)
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+// docxSymbol decodes the character code of a w:sym element. Symbol fonts
+// store their glyphs in the F000-F0FF private range, which maps back onto
+// the plain 8-bit code.
+func docxSymbol(code string) rune {
+	value, err := strconv.ParseUint(code, 16, 32)
+	if err != nil {
+		return ' '
+	}
+	if value >= 0xF000 && value <= 0xF0FF {
+		value -= 0xF000
+	}
+	return rune(value)
+}
+
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// isTOCField reports whether a field instruction builds a table of contents,
+// e.g. ` TOC \o "1-3" \h \z \u `.
+func isTOCField(instr string) bool {
+	fields := strings.Fields(instr)
+	return len(fields) > 0 && fields[0] == "TOC"
+}
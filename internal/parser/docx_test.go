@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func docxBody(paragraphs string) string {
+	return `<w:document xmlns:w="w"><w:body>` + paragraphs + `</w:body></w:document>`
+}
+
+func TestReadDOCXBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"paragraphs", `<w:p><w:r><w:t>Статья 1.</w:t></w:r><w:r><w:t xml:space="preserve"> Предмет</w:t></w:r></w:p><w:p><w:r><w:t>Текст</w:t></w:r></w:p>`,
+			[]string{"Статья 1. Предмет", "Текст"}},
+		{"empty paragraph", `<w:p/><w:p><w:r><w:t>Текст</w:t></w:r></w:p>`,
+			[]string{"", "Текст"}},
+		{"line break", `<w:p><w:r><w:t>Глава 1. ОБЩИЕ</w:t><w:br/><w:t>Статья 1. Предмет</w:t></w:r></w:p>`,
+			[]string{"Глава 1. ОБЩИЕ", "Статья 1. Предмет"}},
+		{"tab", `<w:p><w:r><w:t>Статья 1. Предмет</w:t><w:tab/><w:t>3</w:t></w:r></w:p>`,
+			[]string{"Статья 1. Предмет\t3"}},
+		{"tab stops", `<w:p><w:pPr><w:tabs><w:tab w:val="left" w:pos="720"/><w:tab w:val="right" w:leader="dot" w:pos="9350"/></w:tabs></w:pPr><w:r><w:t>Статья 1. Предмет</w:t></w:r></w:p>`,
+			[]string{"Статья 1. Предмет"}},
+		{"trailing tab", `<w:p><w:r><w:t>Текст</w:t><w:tab/></w:r></w:p>`,
+			[]string{"Текст"}},
+		{"symbol font", `<w:p><w:r><w:sym w:font="Symbol" w:char="F0B7"/><w:t> пункт</w:t></w:r></w:p>`,
+			[]string{"· пункт"}},
+		{"plain symbol", `<w:p><w:r><w:t>Статья</w:t><w:sym w:font="Arial" w:char="2116"/><w:t>5</w:t></w:r></w:p>`,
+			[]string{"Статья№5"}},
+		{"bad symbol", `<w:p><w:r><w:t>а</w:t><w:sym w:char="zz"/><w:t>б</w:t></w:r></w:p>`,
+			[]string{"а б"}},
+		{"non-breaking hyphen", `<w:p><w:r><w:t>Статья 2</w:t><w:noBreakHyphen/><w:t>1.</w:t></w:r></w:p>`,
+			[]string{"Статья 2-1."}},
+	}
+
+	for _, tt := range tests {
+		lines, err := readDOCXBody(strings.NewReader(docxBody(tt.body)), nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, line := range lines {
+			got = append(got, line.Text)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadDOCXStyleNames(t *testing.T) {
+	files := map[string]string{
+		"word/styles.xml": `<w:styles xmlns:w="w">
+<w:style w:type="paragraph" w:styleId="a1"><w:name w:val="heading 1"/></w:style>
+<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="a1"/></w:style>
+<w:style w:type="character" w:styleId="a3"/>
+</w:styles>`,
+		"word/document.xml": docxBody(`<w:p><w:pPr><w:pStyle w:val="a1"/></w:pPr><w:r><w:t>Глава 1. ОБЩИЕ</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Статья 1. Предмет</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="a3"/></w:pPr><w:r><w:t>Текст</w:t></w:r></w:p>
+<w:p><w:r><w:t>Без стиля</w:t></w:r></w:p>`),
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "act.docx")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := readDOCX(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"heading 1", "heading 2", "a3", ""}
+	if len(src.Lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(src.Lines), len(want))
+	}
+	for i, line := range src.Lines {
+		if line.Style != want[i] {
+			t.Errorf("line %d %q: style %q, want %q", i+1, line.Text, line.Style, want[i])
+		}
+	}
+}
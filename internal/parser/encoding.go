@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	textunicode "golang.org/x/text/encoding/unicode"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+var cyrillicCodepages = []struct {
+	name    string
+	charmap *charmap.Charmap
+}{
+	{"windows-1251", charmap.Windows1251},
+	{"koi8-r", charmap.KOI8R},
+	{"ibm866", charmap.CodePage866},
+	{"x-mac-cyrillic", charmap.MacintoshCyrillic},
+}
+
+// cyrillicRank orders lowercase Russian letters from most to least
+// frequent; a wrong code page scatters text over rare letters and case.
+var cyrillicRank = func() map[rune]int {
+	ranks := make(map[rune]int)
+	for i, r := range []rune("оеаинтсрвлкмдпуяыьгзбчйхжшюцщэфъё") {
+		ranks[r] = i
+	}
+	return ranks
+}()
+
+// decodeText detects the encoding of a plain-text upload and returns its
+// name together with the UTF-8 text. BOMs win; otherwise valid UTF-8 is
+// taken as is, and anything else is scored against the Cyrillic code pages.
+func decodeText(data []byte) (string, string) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return "utf-8", string(data[len(bomUTF8):])
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data, textunicode.LittleEndian, "utf-16le")
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data, textunicode.BigEndian, "utf-16be")
+	}
+
+	if order, name, ok := guessUTF16(data); ok {
+		return decodeUTF16(data, order, name)
+	}
+	if utf8.Valid(data) {
+		return "utf-8", string(data)
+	}
+
+	bestName, bestText, bestScore := "", "", 0
+	for i, candidate := range cyrillicCodepages {
+		decoded, err := candidate.charmap.NewDecoder().Bytes(data)
+		if err != nil {
+			continue
+		}
+		text := string(decoded)
+		if score := scoreCyrillic(text); i == 0 || score > bestScore {
+			bestName, bestText, bestScore = candidate.name, text, score
+		}
+	}
+
+	return bestName, bestText
+}
+
+func decodeUTF16(data []byte, order textunicode.Endianness, name string) (string, string) {
+	decoded, err := textunicode.UTF16(order, textunicode.UseBOM).NewDecoder().Bytes(data)
+	if err != nil {
+		return name, string(data)
+	}
+	return name, string(decoded)
+}
+
+// guessUTF16 recognises BOM-less UTF-16 by the zero high bytes that spaces,
+// digits and punctuation leave in every other position. Single-byte text
+// never contains NUL, so even a modest share of them is conclusive.
+func guessUTF16(data []byte) (textunicode.Endianness, string, bool) {
+	if len(data) < 4 || len(data)%2 != 0 {
+		return textunicode.LittleEndian, "", false
+	}
+
+	even, odd := 0, 0
+	for i := 0; i < len(data); i += 2 {
+		if data[i] == 0 {
+			even++
+		}
+		if data[i+1] == 0 {
+			odd++
+		}
+	}
+
+	pairs := len(data) / 2
+	switch {
+	case odd*20 > pairs && even*10 < odd:
+		return textunicode.LittleEndian, "utf-16le", true
+	case even*20 > pairs && odd*10 < even:
+		return textunicode.BigEndian, "utf-16be", true
+	}
+	return textunicode.LittleEndian, "", false
+}
+
+func scoreCyrillic(text string) int {
+	score := 0
+	prevLetter := false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r) && unicode.IsLower(r):
+			if rank, ok := cyrillicRank[r]; ok {
+				score += len(cyrillicRank) - rank/2
+			}
+		case unicode.Is(unicode.Cyrillic, r) && unicode.IsUpper(r):
+			if prevLetter {
+				score -= 10
+			}
+		case r >= 0x2500 && r <= 0x259F, unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t':
+			score -= 20
+		}
+		prevLetter = unicode.IsLetter(r)
+	}
+	return score
+}
+
+// encodedLayout returns the BOM length of a decoded upload and a function
+// measuring decoded text in bytes of its original encoding, so line offsets
+// point into the uploaded file.
+func encodedLayout(data []byte, encoding string) (int, func(string) int) {
+	switch encoding {
+	case "utf-8":
+		if bytes.HasPrefix(data, bomUTF8) {
+			return len(bomUTF8), nil
+		}
+		return 0, nil
+	case "utf-16le", "utf-16be":
+		base := 0
+		if bytes.HasPrefix(data, bomUTF16LE) || bytes.HasPrefix(data, bomUTF16BE) {
+			base = 2
+		}
+		return base, func(s string) int {
+			n := 0
+			for _, r := range s {
+				n += 2
+				if r > 0xFFFF {
+					n += 2
+				}
+			}
+			return n
+		}
+	}
+	return 0, utf8.RuneCountInString
+}
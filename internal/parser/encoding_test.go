@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	textunicode "golang.org/x/text/encoding/unicode"
+)
+
+const encodingSample = "Статья 1. Предмет регулирования\nНастоящий Кодекс регулирует отношения по установлению налогов.\n"
+
+func encodeSample(t *testing.T, enc encoding.Encoding) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(encodingSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+	}{
+		{"utf-8", []byte(encodingSample), "utf-8"},
+		{"utf-8 with BOM", append(append([]byte(nil), bomUTF8...), encodingSample...), "utf-8"},
+		{"windows-1251", encodeSample(t, charmap.Windows1251), "windows-1251"},
+		{"koi8-r", encodeSample(t, charmap.KOI8R), "koi8-r"},
+		{"ibm866", encodeSample(t, charmap.CodePage866), "ibm866"},
+		{"utf-16le with BOM", encodeSample(t, textunicode.UTF16(textunicode.LittleEndian, textunicode.UseBOM)), "utf-16le"},
+		{"utf-16be with BOM", encodeSample(t, textunicode.UTF16(textunicode.BigEndian, textunicode.UseBOM)), "utf-16be"},
+		{"utf-16le without BOM", encodeSample(t, textunicode.UTF16(textunicode.LittleEndian, textunicode.IgnoreBOM)), "utf-16le"},
+	}
+
+	for _, tt := range tests {
+		name, text := decodeText(tt.data)
+		if name != tt.encoding || text != encodingSample {
+			t.Errorf("%s: got %s %q", tt.name, name, text)
+		}
+	}
+}
+
+func TestReadTXTOffsets(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"windows-1251", encodeSample(t, charmap.Windows1251)},
+		{"utf-16le with BOM", encodeSample(t, textunicode.UTF16(textunicode.LittleEndian, textunicode.UseBOM))},
+		{"utf-8 with BOM", append(append([]byte(nil), bomUTF8...), encodingSample...)},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "act.txt")
+		if err := os.WriteFile(path, tt.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		src, err := readTXT(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(src.Lines) < 2 || src.Lines[1].Text != "Настоящий Кодекс регулирует отношения по установлению налогов." {
+			t.Errorf("%s: lines %+v", tt.name, src.Lines)
+			continue
+		}
+
+		// The second line starts right after the first newline of the file.
+		newline := bytes.IndexByte(tt.data, '\n')
+		if src.Encoding == "utf-16le" {
+			newline++
+		}
+		if got := src.Lines[1].Offset; got != newline+1 {
+			t.Errorf("%s: second line at offset %d, want %d", tt.name, got, newline+1)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// Definition articles are titled "Основные понятия, используемые в
+// настоящем Кодексе" in Russian and "Осы Кодексте пайдаланылатын негізгі
+// ұғымдар" in Kazakh.
+var (
+	ruGlossaryRegex = regexp.MustCompile(`(?i)основные\s+(?:понятия|определения)|(?:понятия|определения),?\s+(?:используемые|применяемые)`)
+	kzGlossaryRegex = regexp.MustCompile(`(?i)негізгі\s+ұғымдар|пайдаланылатын\s+ұғымдар`)
+)
+
+// definitionRegex splits "налогоплательщик – лицо, ..." at the first dash
+// set off by spaces; hyphens inside words are left alone.
+var definitionRegex = regexp.MustCompile(`^(.+?)\s+[–—-]\s+(.+)$`)
+
+// maxTermWords keeps ordinary sentences that happen to contain a dash out
+// of the glossary.
+const maxTermWords = 10
+
+// extractGlossary collects the terms defined in the points and clauses
+// of definition articles.
+func extractGlossary(node *DocumentNode) []models.Term {
+	var terms []models.Term
+	if node.Type == "ARTICLE" {
+		if lang := glossaryLanguage(node); lang != "" {
+			collectTerms(node, lang, &terms)
+			return terms
+		}
+	}
+	for _, child := range node.Children {
+		terms = append(terms, extractGlossary(child)...)
+	}
+	return terms
+}
+
+func glossaryLanguage(article *DocumentNode) string {
+	switch {
+	case kzGlossaryRegex.MatchString(article.NameKz) || kzGlossaryRegex.MatchString(article.NameRu):
+		return "kz"
+	case ruGlossaryRegex.MatchString(article.NameRu):
+		return "ru"
+	}
+	return ""
+}
+
+func collectTerms(node *DocumentNode, lang string, terms *[]models.Term) {
+	for _, child := range node.Children {
+		if child.Type != "POINT" && child.Type != "CLAUSE" {
+			continue
+		}
+		if term, ok := parseDefinition(child, lang); ok {
+			*terms = append(*terms, term)
+		}
+		collectTerms(child, lang, terms)
+	}
+}
+
+func parseDefinition(node *DocumentNode, lang string) (models.Term, bool) {
+	var parts []string
+	for _, s := range []string{node.NameRu, node.NameKz, node.TextRu, node.TextKz} {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	text := strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+
+	match := definitionRegex.FindStringSubmatch(text)
+	if match == nil {
+		return models.Term{}, false
+	}
+	term := strings.TrimSpace(match[1])
+	if term == "" || strings.ContainsAny(term, ":;") || len(strings.Fields(term)) > maxTermWords {
+		return models.Term{}, false
+	}
+
+	return models.Term{
+		NodeType:          node.Type,
+		NodeNumber:        node.ID,
+		ParentPointID:     node.ParentIDs["POINT"],
+		ParentArticleID:   node.ParentIDs["ARTICLE"],
+		ParentParagraphID: node.ParentIDs["PARAGRAPH"],
+		ParentChapterID:   node.ParentIDs["CHAPTER"],
+		ParentSectionID:   node.ParentIDs["SECTION"],
+		ParentPartID:      node.ParentIDs["PART"],
+		Language:          lang,
+		Term:              term,
+		Definition:        strings.TrimRight(strings.TrimSpace(match[2]), ";."),
+		Active:            node.inForce(),
+		Source:            node.source(),
+	}, true
+}
@@ -0,0 +1,38 @@
+package parser
+
+import "testing"
+
+func TestGlossaryTermsAreSplit(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(`Статья 1. Основные понятия, используемые в настоящем Кодексе
+1. В настоящем Кодексе используются следующие основные понятия:
+1) налогоплательщик – лицо, которое обязано уплачивать налоги;
+2) налоговый агент — лицо, на которое возложена обязанность
+по исчислению и удержанию налогов;
+3) исключен Законом РК от 10.01.2018 № 133-VI;
+Статья 2. Принципы
+1) законность – принцип, по которому налоги устанавливаются законом.
+1-бап. Осы Кодексте пайдаланылатын негізгі ұғымдар
+1) салық төлеуші – салық төлеуге міндетті тұлға;`)
+	terms := p.ConvertToFlatData().Glossary
+
+	want := []struct {
+		lang, number, term, definition string
+	}{
+		{"ru", "1", "налогоплательщик", "лицо, которое обязано уплачивать налоги"},
+		{"ru", "2", "налоговый агент", "лицо, на которое возложена обязанность по исчислению и удержанию налогов"},
+		{"kz", "1", "салық төлеуші", "салық төлеуге міндетті тұлға"},
+	}
+	if len(terms) != len(want) {
+		t.Fatalf("got %d terms, want %d: %+v", len(terms), len(want), terms)
+	}
+	for i, w := range want {
+		term := terms[i]
+		if term.Language != w.lang || term.NodeNumber != w.number || term.Term != w.term || term.Definition != w.definition {
+			t.Errorf("term %d: got %+v, want %+v", i, term, w)
+		}
+	}
+	if terms[0].ParentArticleID != "1" || terms[0].ParentPointID != "1" {
+		t.Errorf("term parents: article %q point %q, want 1 1", terms[0].ParentArticleID, terms[0].ParentPointID)
+	}
+}
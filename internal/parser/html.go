@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+var htmlSkippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Template: true,
+}
+
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Div:        true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Li:         true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Dl:         true,
+	atom.Dt:         true,
+	atom.Dd:         true,
+	atom.Tr:         true,
+	atom.Td:         true,
+	atom.Th:         true,
+	atom.Table:      true,
+	atom.Blockquote: true,
+	atom.Pre:        true,
+	atom.Section:    true,
+	atom.Article:    true,
+	atom.Center:     true,
+	atom.Hr:         true,
+}
+
+// htmlNavigationMarkers catch the portal's menus and breadcrumbs, which are
+// plain divs rather than <nav> elements.
+var htmlNavigationMarkers = []string{"nav", "menu", "breadcrumb", "sidebar", "banner"}
+
+type htmlReader struct {
+	lines  []Line
+	text   strings.Builder
+	style  string
+	anchor string
+	inPre  bool
+}
+
+func readHTML(filePath string) (*Source, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading html: %v", err)
+	}
+
+	encoding, name, _ := charset.DetermineEncoding(content, "text/html")
+	decoded, err := encoding.NewDecoder().Bytes(content)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding html: %v", err)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(decoded))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing html: %v", err)
+	}
+
+	r := &htmlReader{}
+	r.walk(doc)
+	r.flush()
+
+	return &Source{Encoding: name, Lines: r.lines}, nil
+}
+
+func (r *htmlReader) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.writeText(n.Data)
+		return
+	case html.ElementNode:
+		if htmlSkippedElements[n.DataAtom] || isHTMLNavigation(n) {
+			return
+		}
+	}
+
+	if n.Type == html.ElementNode {
+		if id := htmlAttr(n, "id"); id != "" {
+			r.anchor = id
+		} else if n.DataAtom == atom.A && htmlAttr(n, "name") != "" {
+			r.anchor = htmlAttr(n, "name")
+		}
+	}
+
+	block := n.Type == html.ElementNode && htmlBlockElements[n.DataAtom]
+	if block {
+		r.flush()
+		if strings.HasPrefix(n.Data, "h") && len(n.Data) == 2 {
+			r.style = n.Data
+		}
+	}
+	if n.DataAtom == atom.Br {
+		r.flush()
+	}
+
+	wasPre := r.inPre
+	if n.DataAtom == atom.Pre {
+		r.inPre = true
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		r.walk(child)
+	}
+	r.inPre = wasPre
+
+	if block {
+		r.flush()
+	}
+}
+
+func (r *htmlReader) writeText(text string) {
+	if r.inPre {
+		parts := strings.Split(text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				r.flush()
+			}
+			r.text.WriteString(part)
+		}
+		return
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		if text != "" && r.text.Len() > 0 {
+			r.text.WriteByte(' ')
+		}
+		return
+	}
+	if isHTMLSpace(text[0]) && r.text.Len() > 0 {
+		r.text.WriteByte(' ')
+	}
+	r.text.WriteString(strings.Join(fields, " "))
+	if isHTMLSpace(text[len(text)-1]) {
+		r.text.WriteByte(' ')
+	}
+}
+
+// flush closes the current line. The anchor stays pending until a line with
+// text picks it up, so <a name="z5"></a> placed before a heading lands on
+// the heading itself.
+func (r *htmlReader) flush() {
+	text := strings.TrimSpace(r.text.String())
+	r.text.Reset()
+	if text == "" {
+		return
+	}
+
+	r.lines = append(r.lines, Line{Text: text, Style: r.style, Anchor: r.anchor})
+	r.style = ""
+	r.anchor = ""
+}
+
+func isHTMLNavigation(n *html.Node) bool {
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Html {
+		return false
+	}
+	if htmlAttr(n, "role") == "navigation" {
+		return true
+	}
+
+	classes := strings.ToLower(htmlAttr(n, "class") + " " + htmlAttr(n, "id"))
+	for _, word := range strings.FieldsFunc(classes, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}) {
+		for _, marker := range htmlNavigationMarkers {
+			if word == marker {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func htmlAttr(n *html.Node, name string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
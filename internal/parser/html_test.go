@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readTestHTML(t *testing.T, content string) *Source {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "act.html")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := readHTML(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestReadHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"script and style", `<script>var x = "Статья 9. Скрипт";</script><style>p { color: red }</style><p>Статья 1. Предмет</p>`,
+			[]string{"Статья 1. Предмет"}},
+		{"navigation", `<nav><a href="/">Главная</a></nav><div class="main-menu">Статья 9. Меню</div><div id="breadcrumb">Кодексы</div><div role="navigation">Поиск</div><p>Статья 1. Предмет</p>`,
+			[]string{"Статья 1. Предмет"}},
+		{"entities", `<p>Закон &laquo;О&nbsp;налогах&raquo; &#8470;&nbsp;120&#x2011;VI &amp; др.</p>`,
+			[]string{"Закон «О налогах» № 120\u2011VI & др."}},
+		{"blocks and breaks", `<div>Глава 1. ОБЩИЕ<br>Статья 1. <b>Предмет</b> регулирования</div><ul><li>1) первый;</li><li>2) второй.</li></ul>`,
+			[]string{"Глава 1. ОБЩИЕ", "Статья 1. Предмет регулирования", "1) первый;", "2) второй."}},
+	}
+
+	for _, tt := range tests {
+		src := readTestHTML(t, `<html><head><title>Кодекс</title></head><body>`+tt.body+`</body></html>`)
+		var got []string
+		for _, line := range src.Lines {
+			got = append(got, line.Text)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLAnchorsReachSourcePosition(t *testing.T) {
+	src := readTestHTML(t, `<html><body>
+<a name="z1"></a><h3>Статья 1. Предмет</h3>
+<p>Текст.</p>
+<p id="z2">Статья 2. Принципы</p>
+<p>Сноска. Статья 2 с изменениями, внесенными Законом РК от 10.01.2018 № 133-VI.</p>
+</body></html>`)
+
+	anchors := make(map[string]string)
+	for _, line := range src.Lines {
+		anchors[line.Text] = line.Anchor
+	}
+	if anchors["Статья 1. Предмет"] != "z1" || anchors["Статья 2. Принципы"] != "z2" || anchors["Текст."] != "" {
+		t.Errorf("anchors = %v", anchors)
+	}
+
+	p := NewParser()
+	p.ParseLines(src.Lines)
+	data := p.ConvertToFlatData()
+	if len(data.Articles) != 2 || data.Articles[0].Source.Anchor != "z1" || data.Articles[1].Source.Anchor != "z2" {
+		t.Errorf("articles %+v", data.Articles)
+	}
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// maxPreambleLines bounds how many lines before the first heading are kept
+// for the metadata.
+const maxPreambleLines = 20
+
+var (
+	// actNumberRegex finds the registration number, "№ 120-VI".
+	actNumberRegex = regexp.MustCompile(`№\s*([^\s«»"“”,;]+)`)
+	// ruActDateRegex matches "25 декабря 2017 года", kzActDateRegex
+	// "2017 жылғы 25 желтоқсандағы".
+	ruActDateRegex = regexp.MustCompile(`(\d{1,2})\s+(\p{L}+)\s+(\d{4})`)
+	kzActDateRegex = regexp.MustCompile(`(\d{4})\s+жылғы\s+(\d{1,2})\s+(\p{L}+)`)
+	actTitleRegex  = regexp.MustCompile(`[«"“]([^»"”]+)[»"”]`)
+)
+
+// actMonths lists the month names of both languages as they start in a
+// date: genitive in Russian, with the locative suffix in Kazakh.
+var actMonths = [][]string{
+	{"января", "қаңтар"},
+	{"февраля", "ақпан"},
+	{"марта", "наурыз"},
+	{"апреля", "сәуір"},
+	{"мая", "мамыр"},
+	{"июня", "маусым"},
+	{"июля", "шілде"},
+	{"августа", "тамыз"},
+	{"сентября", "қыркүйек"},
+	{"октября", "қазан"},
+	{"ноября", "қараша"},
+	{"декабря", "желтоқсан"},
+}
+
+// parseMetadata reads the act's type, date, number, title and language from
+// the lines before the first heading. The header line is the first one
+// with a "№" and a date. The title is the quoted name in the header or,
+// in the consolidated texts that print it above the header, the first line.
+func parseMetadata(preamble []Line) models.Document {
+	var doc models.Document
+	header := -1
+	for i, line := range preamble {
+		text := strings.TrimSpace(line.Text)
+		number := actNumberRegex.FindStringSubmatchIndex(text)
+		if number == nil {
+			continue
+		}
+		// A date that does not exist is left out, but the line is still
+		// the header.
+		date, at := actDate(text)
+		if at < 0 {
+			continue
+		}
+
+		header = i
+		doc.Date = date
+		doc.Number = strings.TrimRight(text[number[2]:number[3]], ".")
+		if number[0] < at {
+			at = number[0]
+		}
+		doc.ActType = noteActType(strings.ToLower(text[:at]))
+		if title := actTitleRegex.FindStringSubmatch(text); title != nil {
+			doc.Title = strings.TrimSpace(title[1])
+		}
+		break
+	}
+	if header < 0 {
+		return doc
+	}
+
+	if doc.Title == "" {
+		for i, line := range preamble {
+			text := strings.TrimSpace(line.Text)
+			if i != header && text != "" {
+				doc.Title = strings.TrimRight(text, ".")
+				break
+			}
+		}
+	}
+
+	doc.Language = "ru"
+	if isKazakhText(preamble[header].Text) {
+		doc.Language = "kz"
+	}
+	return doc
+}
+
+// actDate returns the ISO date written in text and where it starts, or -1
+// when text has no date. The date is "" when it does not exist.
+func actDate(text string) (string, int) {
+	if m := kzActDateRegex.FindStringSubmatchIndex(text); m != nil {
+		if month := actMonth(text[m[6]:m[7]]); month > 0 {
+			return calendarDate(atoi(text[m[2]:m[3]]), month, atoi(text[m[4]:m[5]])), m[0]
+		}
+	}
+	if m := ruActDateRegex.FindStringSubmatchIndex(text); m != nil {
+		if month := actMonth(text[m[4]:m[5]]); month > 0 {
+			return calendarDate(atoi(text[m[6]:m[7]]), month, atoi(text[m[2]:m[3]])), m[0]
+		}
+	}
+	if m := statusDate.FindStringSubmatchIndex(text); m != nil {
+		return isoDate([]string{"", text[m[2]:m[3]], text[m[4]:m[5]], text[m[6]:m[7]]}), m[0]
+	}
+	return "", -1
+}
+
+func actMonth(word string) int {
+	word = strings.ToLower(word)
+	for i, names := range actMonths {
+		for _, name := range names {
+			if strings.HasPrefix(word, name) {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		preamble []string
+		want     models.Document
+	}{
+		{
+			[]string{"Кодекс Республики Казахстан от 25 декабря 2017 года № 120-VI ЗРК «О налогах и других обязательных платежах в бюджет»"},
+			models.Document{ActType: "Кодекс", Date: "2017-12-25", Number: "120-VI", Title: "О налогах и других обязательных платежах в бюджет", Language: "ru"},
+		},
+		{
+			[]string{"О браке (супружестве) и семье.", "Кодекс Республики Казахстан от 26.12.2011 № 518-IV."},
+			models.Document{ActType: "Кодекс", Date: "2011-12-26", Number: "518-IV", Title: "О браке (супружестве) и семье", Language: "ru"},
+		},
+		{
+			[]string{"Салық және бюджетке төленетін басқа да міндетті төлемдер туралы (Салық кодексі)", "Қазақстан Республикасының Кодексі 2017 жылғы 25 желтоқсандағы № 120-VI ҚРЗ."},
+			models.Document{ActType: "Кодекс", Date: "2017-12-25", Number: "120-VI", Title: "Салық және бюджетке төленетін басқа да міндетті төлемдер туралы (Салық кодексі)", Language: "kz"},
+		},
+		{
+			[]string{"Закон Республики Казахстан от 31.02.2020 № 300-VI «Об опечатке в дате»"},
+			models.Document{ActType: "Закон", Number: "300-VI", Title: "Об опечатке в дате", Language: "ru"},
+		},
+		{
+			[]string{"Закон Республики Казахстан от 30 февраля 2020 года № 301-VI «О несуществующем дне»"},
+			models.Document{ActType: "Закон", Number: "301-VI", Title: "О несуществующем дне", Language: "ru"},
+		},
+		{
+			[]string{"Настоящий Кодекс регулирует налоговые отношения."},
+			models.Document{},
+		},
+	}
+
+	for _, tt := range tests {
+		var lines []Line
+		for _, text := range tt.preamble {
+			lines = append(lines, Line{Text: text})
+		}
+		if got := parseMetadata(lines); got != tt.want {
+			t.Errorf("parseMetadata(%q) = %+v, want %+v", tt.preamble, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeepsPreamble(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument("Закон Республики Казахстан от 10 января 2018 года № 133-VI «О здравоохранении»\n\nСтатья 1. Основные понятия\nТекст.\n")
+	doc := p.ConvertToFlatData().Document
+	if doc.ActType != "Закон" || doc.Number != "133-VI" || doc.Date != "2018-01-10" {
+		t.Errorf("got %+v", doc)
+	}
+}
@@ -0,0 +1,242 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// Normalization selects the rewrites applied to every line before it is
+// matched. OCR output and hand-typed files put Latin lookalikes into
+// Cyrillic words ("Cтатья" with a Latin C), non-breaking spaces and soft
+// hyphens into headings, and write "№" in several ways; any of these keeps
+// a pattern from matching. Headings are read from the normalized text, body
+// text keeps the original.
+type Normalization struct {
+	// Homoglyphs replaces Latin letters that look like Cyrillic ones inside
+	// words that are otherwise Cyrillic.
+	Homoglyphs bool
+	// Spaces turns non-breaking and other typographic spaces into plain
+	// spaces.
+	Spaces bool
+	// Invisible drops soft hyphens and zero-width characters.
+	Invisible bool
+	// NumberSign writes "N°", "Nº", "No." and "N" before a number as "№".
+	NumberSign bool
+	// Quotes turns pairs of „ “ ” quotes into « ».
+	Quotes bool
+}
+
+// DefaultNormalization applies every rewrite.
+var DefaultNormalization = Normalization{
+	Homoglyphs: true,
+	Spaces:     true,
+	Invisible:  true,
+	NumberSign: true,
+	Quotes:     true,
+}
+
+// Kinds of change reported in models.NormalizationChange.
+const (
+	normalizeHomoglyphs = "homoglyphs"
+	normalizeSpaces     = "spaces"
+	normalizeInvisible  = "invisible"
+	normalizeNumberSign = "numberSign"
+	normalizeQuotes     = "quotes"
+)
+
+var normalizeKinds = []string{normalizeHomoglyphs, normalizeSpaces, normalizeInvisible, normalizeNumberSign, normalizeQuotes}
+
+// homoglyphs maps Latin letters to the Cyrillic letters they pass for in
+// both languages.
+var homoglyphs = map[rune]rune{
+	'A': 'А', 'B': 'В', 'C': 'С', 'E': 'Е', 'H': 'Н', 'K': 'К', 'M': 'М',
+	'O': 'О', 'P': 'Р', 'T': 'Т', 'X': 'Х',
+	'a': 'а', 'c': 'с', 'e': 'е', 'o': 'о', 'p': 'р', 'x': 'х',
+}
+
+// ruHomoglyphs and kzHomoglyphs map the letters whose lookalike depends on
+// the language of the word: Y passes for У in Russian and for Ү in Kazakh,
+// where I, i and h also stand in for І, і and һ.
+var (
+	ruHomoglyphs = map[rune]rune{'Y': 'У', 'y': 'у'}
+	kzHomoglyphs = map[rune]rune{'Y': 'Ү', 'y': 'ү', 'I': 'І', 'i': 'і', 'h': 'һ'}
+)
+
+var numberSignRegex = regexp.MustCompile(`(^|[^\p{L}\p{N}])(?:N\s?[°º]|No\.?|N)(\s*\d)`)
+
+// quoteRegex matches a quoted span in „German“ or “English” style. „ “ are
+// opening or closing depending on the style, so only pairs are rewritten.
+var quoteRegex = regexp.MustCompile(`[„“]([^„“”«»]*)[“”]`)
+
+// normalize rewrites text and returns it with the kinds of change made.
+func (n Normalization) normalize(text string) (string, []string) {
+	if isPlainText(text) && !strings.Contains(text, "N") {
+		return text, nil
+	}
+
+	var kinds []string
+	if n.Invisible {
+		if out := strings.Map(func(r rune) rune {
+			if isInvisible(r) {
+				return -1
+			}
+			return r
+		}, text); out != text {
+			text = out
+			kinds = append(kinds, normalizeInvisible)
+		}
+	}
+	if n.Spaces {
+		if out := strings.Map(func(r rune) rune {
+			if r != ' ' && r != '\t' && unicode.IsSpace(r) {
+				return ' '
+			}
+			return r
+		}, text); out != text {
+			text = out
+			kinds = append(kinds, normalizeSpaces)
+		}
+	}
+	if n.Homoglyphs {
+		if out := replaceHomoglyphs(text); out != text {
+			text = out
+			kinds = append(kinds, normalizeHomoglyphs)
+		}
+	}
+	if n.NumberSign && strings.Contains(text, "N") {
+		if out := numberSignRegex.ReplaceAllString(text, "${1}№${2}"); out != text {
+			text = out
+			kinds = append(kinds, normalizeNumberSign)
+		}
+	}
+	if n.Quotes && strings.ContainsAny(text, "„“") {
+		if out := quoteRegex.ReplaceAllString(text, "«${1}»"); out != text {
+			text = out
+			kinds = append(kinds, normalizeQuotes)
+		}
+	}
+	return text, kinds
+}
+
+// isPlainText reports whether text has nothing but ASCII and Cyrillic
+// letters, digits and plain punctuation, which is most lines, and cannot
+// need any rewrite but the number sign.
+func isPlainText(text string) bool {
+	hasLatin, hasCyrillic := false, false
+	for _, r := range text {
+		switch {
+		case r < 0x80:
+			if r|0x20 >= 'a' && r|0x20 <= 'z' {
+				hasLatin = true
+			}
+		case r >= 0x400 && r <= 0x4ff:
+			hasCyrillic = true
+		case r == '«' || r == '»' || r == '№' || r == '–' || r == '—' || r == '…':
+		default:
+			return false
+		}
+	}
+	return !(hasLatin && hasCyrillic)
+}
+
+// isInvisible reports soft hyphens, zero-width spaces and joiners, the word
+// joiner and the byte order mark.
+func isInvisible(r rune) bool {
+	switch r {
+	case '\u00ad', '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+		return true
+	}
+	return false
+}
+
+// replaceHomoglyphs rewrites the Latin lookalikes in every word that also
+// has Cyrillic letters. Words written wholly in Latin are left alone. A word
+// is read as Kazakh when it has a Kazakh letter or a Latin letter that only
+// passes for one.
+func replaceHomoglyphs(text string) string {
+	var b strings.Builder
+	changed := 0
+	for start := 0; start < len(text); {
+		r, size := utf8.DecodeRuneInString(text[start:])
+		if !unicode.IsLetter(r) {
+			start += size
+			continue
+		}
+		end := start
+		cyrillic, kazakh, lookalike := false, false, false
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !unicode.IsLetter(r) {
+				break
+			}
+			cyrillic = cyrillic || unicode.Is(unicode.Cyrillic, r)
+			kazakh = kazakh || isKazakhLetter(r) || r == 'I' || r == 'i' || r == 'h'
+			_, ok := homoglyphs[r]
+			_, kz := kzHomoglyphs[r]
+			lookalike = lookalike || ok || kz
+			end += size
+		}
+		if cyrillic && lookalike {
+			languageHomoglyphs := ruHomoglyphs
+			if kazakh {
+				languageHomoglyphs = kzHomoglyphs
+			}
+			b.WriteString(text[changed:start])
+			for _, r := range text[start:end] {
+				if c, ok := homoglyphs[r]; ok {
+					r = c
+				} else if c, ok := languageHomoglyphs[r]; ok {
+					r = c
+				}
+				b.WriteRune(r)
+			}
+			changed = end
+		}
+		start = end
+	}
+	if changed == 0 {
+		return text
+	}
+	b.WriteString(text[changed:])
+	return b.String()
+}
+
+// normalizeLine rewrites the text of line for matching, keeps the text it
+// replaced in Original and counts the change.
+func (p *Parser) normalizeLine(line Line) Line {
+	text, kinds := p.normalization.normalize(line.Text)
+	if len(kinds) == 0 {
+		return line
+	}
+
+	line.Original = line.Text
+	line.Text = text
+	if p.normalized == nil {
+		p.normalized = make(map[string]*models.NormalizationChange)
+	}
+	for _, kind := range kinds {
+		change := p.normalized[kind]
+		if change == nil {
+			change = &models.NormalizationChange{Kind: kind, FirstLine: line.Number}
+			p.normalized[kind] = change
+		}
+		change.Count++
+	}
+	return line
+}
+
+// normalizationChanges lists the changes counted since the parse began, in
+// a fixed order.
+func (p *Parser) normalizationChanges() []models.NormalizationChange {
+	var changes []models.NormalizationChange
+	for _, kind := range normalizeKinds {
+		if change := p.normalized[kind]; change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	return changes
+}
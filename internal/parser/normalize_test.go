@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+		kinds    int
+	}{
+		{"Cтатья 5. Налоги", "Статья 5. Налоги", 1},
+		{"Глaва 2. ПРИНЦИПЫ", "Глава 2. ПРИНЦИПЫ", 1},
+		{"Статья\u00a05.\u00a0Нало\u00adги", "Статья 5. Налоги", 2},
+		{"от 10.01.2018 года N 133-VI", "от 10.01.2018 года № 133-VI", 1},
+		{"Закон „О налогах“", "Закон «О налогах»", 1},
+		{"Yчет налогоплательщиков", "Учет налогоплательщиков", 1},
+		{"1-бап. Кодекстiң мақсаты", "1-бап. Кодекстің мақсаты", 1},
+		{"YКIМЕТ ҚАУЛЫСЫ", "ҮКІМЕТ ҚАУЛЫСЫ", 1},
+		{"Yкімет шешiмi", "Үкімет шешімі", 1},
+		{"жиhаз", "жиһаз", 1},
+		{"Closed by Apple Inc. in 2018", "Closed by Apple Inc. in 2018", 0},
+		{"Статья 1. Предмет регулирования", "Статья 1. Предмет регулирования", 0},
+	}
+
+	for _, tt := range tests {
+		got, kinds := DefaultNormalization.normalize(tt.in)
+		if got != tt.want || len(kinds) != tt.kinds {
+			t.Errorf("normalize(%q) = %q, %v; want %q with %d kinds", tt.in, got, kinds, tt.want, tt.kinds)
+		}
+	}
+}
+
+func TestNormalizationKeepsBodyText(t *testing.T) {
+	text := "Cтатья 1. Предмет\nТекст с\u00a0неразрывным пробелом.\n"
+
+	doc, err := Parse(context.Background(), testSource(text), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Data.Articles) != 1 || doc.Data.Articles[0].TextRu != "Текст с\u00a0неразрывным пробелом." {
+		t.Errorf("got articles %+v", doc.Data.Articles)
+	}
+	if len(doc.Data.Normalized) != 2 || doc.Data.Normalized[0].Kind != "homoglyphs" || doc.Data.Normalized[0].FirstLine != 1 {
+		t.Errorf("got changes %+v", doc.Data.Normalized)
+	}
+
+	doc, err = Parse(context.Background(), testSource(text), Options{Normalization: &Normalization{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Data.Articles) != 0 || len(doc.Data.Normalized) != 0 {
+		t.Errorf("parse without normalization got %d articles, changes %+v", len(doc.Data.Articles), doc.Data.Normalized)
+	}
+}
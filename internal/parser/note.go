@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// noteRegex matches the editorial notes of consolidated texts: "Сноска." in
+// the Russian edition, "Ескерту." in the Kazakh one.
+var noteRegex = regexp.MustCompile(`^(?i:сноска|ескерту)\s*[.:]\s*(.*)$`)
+
+// amendingActRegex finds the date and number of an amending act, written
+// "от 10.01.2018 № 133-VI" in Russian and "10.01.2018 № 133-VI" in Kazakh.
+var amendingActRegex = regexp.MustCompile(`(\d{1,2})\.(\d{1,2})\.(\d{4})(?:\s*(?:г\.|года|ж\.))?\s*№\s*([^\s,;()]+)`)
+
+// noteChanges maps word stems of both languages to the kind of change.
+var noteChanges = []struct {
+	stem   string
+	change string
+}{
+	{"изменени", "изменения"},
+	{"өзгеріс", "изменения"},
+	{"дополнени", "дополнения"},
+	{"толықтыру", "дополнения"},
+	{"исключен", "исключена"},
+	{"алып таста", "исключена"},
+	{"в редакции", "в редакции"},
+	{"редакцияда", "в редакции"},
+}
+
+var noteActTypes = []struct {
+	stem    string
+	actType string
+}{
+	{"кодекс", "Кодекс"},
+	{"закон", "Закон"},
+	{"заң", "Закон"},
+	{"указ", "Указ"},
+	{"жарлы", "Указ"},
+	{"постановлени", "Постановление"},
+	{"қаулы", "Постановление"},
+	{"приказ", "Приказ"},
+	{"бұйрық", "Приказ"},
+}
+
+var noteFilter = newRuneFilter(noteRegex)
+
+func isNoteLine(text string) bool {
+	return noteFilter.mayMatch(text) && noteRegex.MatchString(text)
+}
+
+// parseNote turns one note into a record per amending act it cites. A note
+// often lists several acts separated by semicolons; act type and kind of
+// change carry over to the following parts until restated.
+func parseNote(line Line) []models.Note {
+	text := strings.TrimSpace(line.Text)
+	body := text
+	if match := noteRegex.FindStringSubmatch(text); match != nil {
+		body = match[1]
+	}
+
+	var notes []models.Note
+	actType, change := "", ""
+	for _, part := range strings.Split(body, ";") {
+		lower := strings.ToLower(part)
+		if c := noteChange(lower); c != "" {
+			change = c
+		}
+		if a := noteActType(lower); a != "" {
+			actType = a
+		}
+
+		for _, act := range amendingActRegex.FindAllStringSubmatch(part, -1) {
+			notes = append(notes, models.Note{
+				Change:    change,
+				ActType:   actType,
+				ActDate:   isoDate(act[:4]),
+				ActNumber: strings.TrimRight(act[4], "."),
+				Text:      text,
+			})
+		}
+	}
+
+	if len(notes) == 0 {
+		notes = append(notes, models.Note{Change: change, ActType: actType, Text: text})
+	}
+	for i := range notes {
+		notes[i].Source = models.SourcePosition{
+			Line:    line.Number,
+			Offset:  line.Offset,
+			Page:    line.Page,
+			Anchor:  line.Anchor,
+			Heading: text,
+			Pattern: "note",
+		}
+	}
+	return notes
+}
+
+func noteChange(lower string) string {
+	var changes []string
+	for _, c := range noteChanges {
+		if strings.Contains(lower, c.stem) && !containsString(changes, c.change) {
+			changes = append(changes, c.change)
+		}
+	}
+	return strings.Join(changes, ", ")
+}
+
+func noteActType(lower string) string {
+	first, actType := -1, ""
+	for _, a := range noteActTypes {
+		if i := strings.Index(lower, a.stem); i >= 0 && (first < 0 || i < first) {
+			first, actType = i, a.actType
+		}
+	}
+	return actType
+}
+
+func atoi(s string) int {
+	n, _ := parseArabic(s)
+	return n
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
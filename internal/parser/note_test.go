@@ -0,0 +1,36 @@
+package parser
+
+import "testing"
+
+func TestNotesAreAttachedAndParsed(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(`Статья 5. Налоговые агенты
+Сноска. Статья 5 с изменениями, внесенными законами РК от 10.01.2018 № 133-VI (вводится в действие с 01.01.2019); от 2.4.2019 № 241-VI.
+1) агент;
+Сноска. Подпункт 1) исключен Указом Президента РК от 05.06.2020 № 300.
+5-бап. Салық агенттері
+Ескерту. 5-бапқа өзгерістер енгізілді - ҚР 10.01.2018 № 133-VI Заңымен.`)
+	notes := p.ConvertToFlatData().Notes
+
+	want := []struct {
+		nodeType, nodeNumber, change, actType, date, number string
+	}{
+		{"ARTICLE", "5", "изменения", "Закон", "2018-01-10", "133-VI"},
+		{"ARTICLE", "5", "изменения", "Закон", "2019-04-02", "241-VI"},
+		{"CLAUSE", "1", "исключена", "Указ", "2020-06-05", "300"},
+		{"ARTICLE", "5", "изменения", "Закон", "2018-01-10", "133-VI"},
+	}
+	if len(notes) != len(want) {
+		t.Fatalf("got %d notes, want %d: %+v", len(notes), len(want), notes)
+	}
+	for i, w := range want {
+		n := notes[i]
+		if n.NodeType != w.nodeType || n.NodeNumber != w.nodeNumber || n.Change != w.change ||
+			n.ActType != w.actType || n.ActDate != w.date || n.ActNumber != w.number {
+			t.Errorf("note %d: got %+v, want %+v", i, n, w)
+		}
+	}
+	if notes[2].ParentArticleID != "5" {
+		t.Errorf("clause note parent article %q, want 5", notes[2].ParentArticleID)
+	}
+}
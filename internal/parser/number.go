@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// designationLetters orders the letters used for subclauses: Latin first,
+// then the Kazakh Cyrillic alphabet, which contains the Russian one.
+var designationLetters = func() map[rune]int {
+	order := make(map[rune]int)
+	for i, r := range []rune("abcdefghijklmnopqrstuvwxyzаәбвгғдеёжзийкқлмнңоөпрстуұүфхһцчшщъыіьэюя") {
+		order[r] = i + 1
+	}
+	return order
+}()
+
+var romanDigits = map[rune]int{'I': 1, 'V': 5, 'X': 10, 'L': 50, 'C': 100, 'D': 500, 'M': 1000}
+
+// sortKey normalizes a designation such as "65-1", "12-2", "II" or "ә" into
+// a key that sorts lexically in document order: every hyphen-separated
+// component becomes a zero-padded number, so "65-1" lands between "65" and
+// "66" and "9" before "10".
+func sortKey(designation string) string {
+	components := strings.FieldsFunc(designation, func(r rune) bool {
+		return r == '-' || r == '.' || unicode.IsSpace(r)
+	})
+
+	keys := make([]string, 0, len(components))
+	for _, component := range components {
+		keys = append(keys, fmt.Sprintf("%05d", componentValue(component)))
+	}
+	return strings.Join(keys, ".")
+}
+
+func componentValue(component string) int {
+	if value, ok := parseArabic(component); ok {
+		return value
+	}
+	if value, ok := parseRoman(component); ok {
+		return value
+	}
+	if runes := []rune(strings.ToLower(component)); len(runes) == 1 {
+		return designationLetters[runes[0]]
+	}
+	return 0
+}
+
+func parseArabic(s string) (int, bool) {
+	value := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		value = value*10 + int(r-'0')
+	}
+	return value, s != ""
+}
+
+// parseRoman reads Roman numerals. A single letter only counts when it is
+// an upper-case I, V or X; "c)" or "D)" are subclause letters.
+func parseRoman(s string) (int, bool) {
+	if len(s) == 1 && !strings.ContainsAny(s, "IVX") {
+		return 0, false
+	}
+
+	value, prev := 0, 0
+	runes := []rune(strings.ToUpper(s))
+	for i := len(runes) - 1; i >= 0; i-- {
+		digit, ok := romanDigits[runes[i]]
+		if !ok {
+			return 0, false
+		}
+		if digit < prev {
+			value -= digit
+		} else {
+			value += digit
+			prev = digit
+		}
+	}
+	return value, true
+}
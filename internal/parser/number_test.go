@@ -0,0 +1,62 @@
+package parser
+
+import "testing"
+
+func TestSortKey(t *testing.T) {
+	tests := []struct {
+		designation string
+		key         string
+	}{
+		{"65", "00065"},
+		{"65-1", "00065.00001"},
+		{"12-2", "00012.00002"},
+		{"1.1", "00001.00001"},
+		{"II", "00002"},
+		{"а", "00027"},
+		{"ә", "00028"},
+		{"c", "00003"},
+		{"Ә", "00028"},
+	}
+	for _, tt := range tests {
+		if got := sortKey(tt.designation); got != tt.key {
+			t.Errorf("sortKey(%q) = %q, want %q", tt.designation, got, tt.key)
+		}
+	}
+
+	orders := [][]string{
+		{"9", "10", "12", "12-1", "12-2", "12-10", "13", "65", "65-1", "65-2", "66"},
+		{"I", "II", "IV", "V", "IX", "X", "XI"},
+		{"а", "ә", "б", "в", "г", "ғ", "д", "к", "қ", "н", "ң", "о", "ө", "у", "ұ", "ү", "х", "һ", "ы", "і"},
+	}
+	for _, order := range orders {
+		for i := 1; i < len(order); i++ {
+			if prev, next := sortKey(order[i-1]), sortKey(order[i]); prev >= next {
+				t.Errorf("%q (%s) does not sort before %q (%s)", order[i-1], prev, order[i], next)
+			}
+		}
+	}
+}
+
+func TestValidateKazakhNumbering(t *testing.T) {
+	p := NewParser()
+	root := p.ParseDocument(`1-тарау. Жалпы ережелер
+65-бап. Салық
+Мәтін.
+65-1-бап. Салық кезеңі
+1) салық төлеушілер:
+а) мүлік салығы;
+ә) көлік салығы;
+б) әлеуметтік салық;
+66-бап. Қағидалар
+Мәтін.`)
+	if warnings := Validate(root); len(warnings) != 0 {
+		t.Errorf("warnings: %v", warnings)
+	}
+
+	p = NewParser()
+	root = p.ParseDocument("66-бап. Қағидалар\nМәтін.\n65-1-бап. Салық кезеңі\nМәтін.")
+	warnings := Validate(root)
+	if len(warnings) != 1 || warnings[0].Kind != "order" || warnings[0].Number != "65-1" {
+		t.Errorf("out of order: %v", warnings)
+	}
+}
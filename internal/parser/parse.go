@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// contextCheckLines is how often, in lines, a parse looks for cancellation.
+const contextCheckLines = 256
+
+// Options control one call to Parse. The zero value parses with the default
+// profile, reads both languages, sets no limit on nodes and returns
+// validation warnings instead of failing on them.
+type Options struct {
+	// Profile names the rule profile; DefaultProfile when empty.
+	Profile string
+	// Language limits a bilingual act to one edition, "ru" or "kz":
+	// headings of the other language are not matched and its names and
+	// text are left out. Empty reads both.
+	Language string
+	// Strict fails the parse when the validator reports any warning.
+	Strict bool
+	// MaxNodes stops the parse with an error once more nodes than this have
+	// been opened. Zero means no limit.
+	MaxNodes int
+	// Normalization selects the rewrites made before matching;
+	// DefaultNormalization when nil.
+	Normalization *Normalization
+}
+
+// Document is the result of one call to Parse.
+type Document struct {
+	Root *DocumentNode
+	Data *models.CodeData
+}
+
+// Parse parses the lines of src into a new tree. It keeps no state between
+// calls and is safe for concurrent use. It stops with ctx.Err() when ctx is
+// cancelled.
+func Parse(ctx context.Context, src *Source, opts Options) (*Document, error) {
+	p, err := newParserForOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := p.newParseState(nil, true)
+	s.ctx = ctx
+	s.maxNodes = opts.MaxNodes
+	for _, line := range src.Lines {
+		if err := s.feed(p.normalizeLine(line)); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.finish(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	warnings := Validate(p.rootNode)
+	if opts.Strict && len(warnings) > 0 {
+		return nil, fmt.Errorf("document has %d structure warnings, first: %s", len(warnings), warnings[0].Message)
+	}
+
+	data := p.ConvertToFlatData()
+	return &Document{
+		Root: p.rootNode,
+		Data: &models.CodeData{
+			Document:   data.Document,
+			TOC:        data.TOC,
+			Parts:      data.Parts,
+			Sections:   data.Sections,
+			Chapters:   data.Chapters,
+			Paragraphs: data.Paragraphs,
+			Articles:   data.Articles,
+			Points:     data.Points,
+			Clauses:    data.Clauses,
+			SubClauses: data.SubClauses,
+			Notes:      data.Notes,
+			References: data.References,
+			Glossary:   data.Glossary,
+			Normalized: data.Normalized,
+			Warnings:   warnings,
+		},
+	}, nil
+}
+
+// compiledRules are the levels and classifier of one profile for one
+// language. Compiled regexps are safe for concurrent use, so they are built
+// once and shared by every parse.
+type compiledRules struct {
+	levels     []level
+	classifier *classifier
+}
+
+type compiledKey struct {
+	rules    *Rules
+	language string
+}
+
+var (
+	compiledMu sync.Mutex
+	compiled   = make(map[compiledKey]*compiledRules)
+)
+
+func newParserForOptions(opts Options) (*Parser, error) {
+	if opts.Language != "" && opts.Language != "ru" && opts.Language != "kz" {
+		return nil, fmt.Errorf("unknown language: %s", opts.Language)
+	}
+	if opts.MaxNodes < 0 {
+		return nil, fmt.Errorf("invalid node limit: %d", opts.MaxNodes)
+	}
+
+	rules, err := Profile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	key := compiledKey{rules: rules, language: opts.Language}
+	compiledMu.Lock()
+	c, ok := compiled[key]
+	compiledMu.Unlock()
+	if !ok {
+		levels, err := compileLevels(rules)
+		if err != nil {
+			return nil, err
+		}
+		levels = levelsForLanguage(levels, opts.Language)
+		c = &compiledRules{levels: levels, classifier: newClassifier(levels)}
+
+		compiledMu.Lock()
+		compiled[key] = c
+		compiledMu.Unlock()
+	}
+
+	normalization := DefaultNormalization
+	if opts.Normalization != nil {
+		normalization = *opts.Normalization
+	}
+
+	return &Parser{
+		rootNode:      newRootNode(),
+		levels:        c.levels,
+		classifier:    c.classifier,
+		language:      opts.Language,
+		normalization: normalization,
+	}, nil
+}
+
+// levelsForLanguage drops the patterns written for the other language.
+// Patterns with no language are kept.
+func levelsForLanguage(levels []level, language string) []level {
+	if language == "" {
+		return levels
+	}
+
+	filtered := make([]level, len(levels))
+	for i, lvl := range levels {
+		filtered[i] = level{nodeType: lvl.nodeType, optional: lvl.optional}
+		for _, pattern := range lvl.patterns {
+			if pattern.lang == "" || pattern.lang == language {
+				filtered[i].patterns = append(filtered[i].patterns, pattern)
+			}
+		}
+	}
+	return filtered
+}
+
+func textLanguage(text string) string {
+	if isKazakhText(text) {
+		return "kz"
+	}
+	return "ru"
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func testSource(text string) *Source {
+	src := &Source{}
+	lines := newLineReader(strings.NewReader(text))
+	for {
+		line, ok, _ := lines.next()
+		if !ok {
+			return src
+		}
+		src.Lines = append(src.Lines, line)
+	}
+}
+
+func TestParserStartsNewTreeEachCall(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(testCode)
+	first := len(p.ConvertToFlatData().Articles)
+	p.ParseDocument(testCode)
+	if second := len(p.ConvertToFlatData().Articles); second != first {
+		t.Errorf("second parse has %d articles, first %d", second, first)
+	}
+}
+
+func TestParseConcurrent(t *testing.T) {
+	want, err := Parse(context.Background(), testSource(testCode), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doc, err := Parse(context.Background(), testSource(testCode), Options{})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(doc.Data.Articles) != len(want.Data.Articles) {
+				t.Errorf("got %d articles, want %d", len(doc.Data.Articles), len(want.Data.Articles))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParseOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	long := strings.Repeat(testCode+"\n", 50)
+	if _, err := Parse(ctx, testSource(long), Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled parse returned %v", err)
+	}
+
+	if _, err := Parse(context.Background(), testSource(testCode), Options{MaxNodes: 3}); err == nil {
+		t.Error("node limit not enforced")
+	}
+	if _, err := Parse(context.Background(), testSource(testCode), Options{Language: "en"}); err == nil {
+		t.Error("unknown language accepted")
+	}
+
+	bilingual := "Статья 1. Налоги\nНалоги уплачиваются в срок.\n1-бап. Салықтар\nСалықтар мерзімінде төленеді.\n"
+	doc, err := Parse(context.Background(), testSource(bilingual), Options{Language: "ru"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Data.Articles) != 1 || doc.Data.Articles[0].NameKz != "" || doc.Data.Articles[0].TextKz != "" {
+		t.Errorf("Russian parse got %+v", doc.Data.Articles)
+	}
+	doc, err = Parse(context.Background(), testSource(bilingual), Options{Language: "kz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Data.Articles) != 1 || doc.Data.Articles[0].NameKz != "Салықтар" || doc.Data.Articles[0].TextRu != "" {
+		t.Errorf("Kazakh parse got %+v", doc.Data.Articles)
+	}
+}
@@ -1,164 +1,295 @@
 package parser
 
 import (
-	"fmt"
-	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/DonBigBon/parser-backend/internal/models"
 )
 
 type DocumentNode struct {
-	Type      string
-	ID        int
-	NameRu    string
-	NameKz    string
-	ParentIDs map[string]int
-	Children  []*DocumentNode
+	Type          string
+	ID            string
+	SortKey       string
+	NameRu        string
+	NameKz        string
+	TextRu        string
+	TextKz        string
+	Style         string
+	Page          int
+	Anchor        string
+	Line          int
+	Offset        int
+	Heading       string
+	Pattern       string
+	Notes         []Line
+	Status        models.Status
+	EffectiveDate string
+	ParentIDs     map[string]string
+	Parent        *DocumentNode
+	Children      []*DocumentNode
 }
 
+var nodeTypes = []string{"PART", "SECTION", "CHAPTER", "PARAGRAPH", "ARTICLE", "POINT", "CLAUSE", "SUBCLAUSE"}
+
 type Parser struct {
-	rootNode *DocumentNode
-	patterns map[string]*regexp.Regexp
+	rootNode   *DocumentNode
+	levels     []level
+	classifier *classifier
+	language   string
+	preamble   []Line
+	toc        []models.TOCEntry
+
+	normalization Normalization
+	normalized    map[string]*models.NormalizationChange
 }
 
+// NewParser returns a parser for the default "code" profile.
 func NewParser() *Parser {
-	return &Parser{
-		rootNode: &DocumentNode{
-			Type:     "ROOT",
-			Children: make([]*DocumentNode, 0),
-		},
-		patterns: map[string]*regexp.Regexp{
-			"PART":      regexp.MustCompile(`(?m)^(?:\s*)ЧАСТЬ\s+(\d+)[\.\s]+(.+?)(?:\n|$)`),
-			"SECTION":   regexp.MustCompile(`(?m)^(?:\s*)РАЗДЕЛ\s+(\d+)[\.\s]+(.+?)(?:\n|$)`),
-			"CHAPTER":   regexp.MustCompile(`(?m)^(?:\s*)Глава\s+(\d+)[\.\s]+(.+?)(?:\n|$)`),
-			"PARAGRAPH": regexp.MustCompile(`(?m)^(?:\s*)Параграф\s+(\d+)[\.\s]+(.+?)(?:\n|$)`),
-			"ARTICLE":   regexp.MustCompile(`(?m)^(?:\s*)Статья\s+(\d+)[\.\s]+(.+?)(?:\n|$)`),
-			"CLAUSE":    regexp.MustCompile(`(?m)^(?:\s*)(\d+)\)\s+(.+?)(?:\n|$)`),
-			"SUBCLAUSE": regexp.MustCompile(`(?m)^(?:\s*)([a-zа-яA-ZА-Я])\)\s+(.+?)(?:\n|$)`),
-		},
+	p, err := NewParserForProfile(DefaultProfile)
+	if err != nil {
+		panic(err)
 	}
+	return p
 }
 
-func (p *Parser) ParseDocument(content string) *DocumentNode {
-	lines := strings.Split(content, "\n")
+func NewParserForProfile(name string) (*Parser, error) {
+	rules, err := Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewParserFromRules(rules)
+}
 
-	context := make(map[string]*DocumentNode)
-	context["ROOT"] = p.rootNode
+func NewParserFromRules(rules *Rules) (*Parser, error) {
+	levels, err := compileLevels(rules)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	return &Parser{
+		rootNode:      newRootNode(),
+		levels:        levels,
+		classifier:    newClassifier(levels),
+		normalization: DefaultNormalization,
+	}, nil
+}
 
-		p.processLineForType(line, "PART", context)
-		p.processLineForType(line, "SECTION", context)
-		p.processLineForType(line, "CHAPTER", context)
-		p.processLineForType(line, "PARAGRAPH", context)
-		p.processLineForType(line, "ARTICLE", context)
-		p.processLineForType(line, "CLAUSE", context)
-		p.processLineForType(line, "SUBCLAUSE", context)
+func newRootNode() *DocumentNode {
+	return &DocumentNode{
+		Type:     "ROOT",
+		Children: make([]*DocumentNode, 0),
 	}
+}
 
+// ParseDocument parses a whole document held in memory. It streams the text
+// through the same line-by-line parser as ParseReader and keeps the tree.
+// Every call starts a new tree; the parser keeps only the last one, so use
+// Parse when several documents are parsed at once.
+func (p *Parser) ParseDocument(content string) *DocumentNode {
+	// Reading from a string cannot fail and nothing is emitted, so there is
+	// no error to report.
+	p.parseReader(strings.NewReader(content), nil, true)
 	return p.rootNode
 }
 
-func (p *Parser) processLineForType(line, nodeType string, context map[string]*DocumentNode) bool {
-	if match := p.patterns[nodeType].FindStringSubmatch(line); match != nil {
-		nodeID := parseIntID(match[1])
-		nodeName := match[2]
-		nameRu, nameKz := splitNames(nodeName)
+// ParseLines parses lines already extracted by one of the format readers.
+// A table of contents at the start is set aside rather than parsed.
+func (p *Parser) ParseLines(lines []Line) *DocumentNode {
+	s := p.newParseState(nil, true)
+	for _, line := range lines {
+		s.feed(p.normalizeLine(line))
+	}
+	s.finish()
+	return p.rootNode
+}
 
-		newNode := &DocumentNode{
-			Type:      nodeType,
-			ID:        nodeID,
-			NameRu:    nameRu,
-			NameKz:    nameKz,
-			ParentIDs: make(map[string]int),
-			Children:  make([]*DocumentNode, 0),
-		}
+// continuesHeading reports whether next is the wrapped tail of a heading
+// whose name so far is name. The heading must be unfinished, the next line
+// must be neither blank nor a heading of its own, and it has to read as a
+// continuation: lower-case after a normal title, or capitals after a title
+// set in capitals. Body text right under a heading starts with a capital
+// and is left alone.
+func (p *Parser) continuesHeading(name, next string, nextIsHeading bool) bool {
+	if next == "" || nextIsHeading || strings.ContainsAny(lastRune(name), ".;:!?") {
+		return false
+	}
 
-		parentTypes := getParentTypes(nodeType)
+	first := []rune(next)[0]
+	switch {
+	case unicode.IsLower(first):
+		return true
+	case isUpperText(name) && isUpperText(next):
+		return true
+	}
+	return false
+}
 
-		for _, parentType := range parentTypes {
-			if parent, ok := context[parentType]; ok {
-				newNode.ParentIDs[parentType] = parent.ID
-				parent.Children = append(parent.Children, newNode)
-			} else {
-				newNode.ParentIDs[parentType] = 0
-			}
-		}
+func lastRune(s string) string {
+	r, size := utf8.DecodeLastRuneInString(s)
+	if size == 0 {
+		return ""
+	}
+	return string(r)
+}
 
-		if len(parentTypes) == 0 {
-			context["ROOT"].Children = append(context["ROOT"].Children, newNode)
+// isUpperText reports whether text has letters and all of them are capitals.
+func isUpperText(text string) bool {
+	hasLetter := false
+	for _, r := range text {
+		if unicode.IsLower(r) {
+			return false
 		}
-
-		context[nodeType] = newNode
-
-		for _, childType := range getChildTypes(nodeType) {
-			delete(context, childType)
+		if unicode.IsLetter(r) {
+			hasLetter = true
 		}
+	}
+	return hasLetter
+}
 
-		return true
+func (n *DocumentNode) appendText(text string) {
+	// A provision whose whole text is "Исключена ..." is repealed even
+	// when its heading still carries the old name.
+	if n.Status == models.StatusActive && n.TextRu == "" && n.TextKz == "" {
+		n.Status, n.EffectiveDate = detectStatus(text)
 	}
 
-	return false
+	target := &n.TextRu
+	if isKazakhText(text) {
+		target = &n.TextKz
+	}
+
+	if *target != "" {
+		*target += "\n"
+	}
+	*target += text
 }
 
 func (p *Parser) ConvertToFlatData() models.ParsedData {
 	var data models.ParsedData
 
-	p.traverseTree(p.rootNode, &data)
+	data.Document = parseMetadata(p.preamble)
+	data.TOC = p.toc
+	data.Normalized = p.normalizationChanges()
+	p.traverseTree(p.rootNode, true, &data)
+	data.References = extractReferences(p.rootNode)
+	data.Glossary = extractGlossary(p.rootNode)
 
 	return data
 }
 
-func (p *Parser) traverseTree(node *DocumentNode, data *models.ParsedData) {
+// traverseTree flattens the tree. A node counts as active only when it and
+// all its ancestors are in force: the clauses of a suspended article are
+// suspended with it.
+func (p *Parser) traverseTree(node *DocumentNode, active bool, data *models.ParsedData) {
+	active = active && (node.Status == "" || node.Status == models.StatusActive)
+	appendFlat(node, active, data)
+
+	for _, child := range node.Children {
+		p.traverseTree(child, active, data)
+	}
+}
+
+// appendFlat adds one node and its notes to the flat data.
+func appendFlat(node *DocumentNode, active bool, data *models.ParsedData) {
 	switch node.Type {
 	case "PART":
 		data.Parts = append(data.Parts, models.Part{
-			ID:     node.ID,
-			NameRu: node.NameRu,
-			NameKz: node.NameKz,
+			ID:            node.ID,
+			SortKey:       node.SortKey,
+			NameRu:        node.NameRu,
+			NameKz:        node.NameKz,
+			Source:        node.source(),
+			Status:        node.Status,
+			EffectiveDate: node.EffectiveDate,
+			Active:        active,
 		})
 	case "SECTION":
 		data.Sections = append(data.Sections, models.Section{
-			ID:           node.ID,
-			ParentPartID: node.ParentIDs["PART"],
-			NameRu:       node.NameRu,
-			NameKz:       node.NameKz,
+			ID:            node.ID,
+			SortKey:       node.SortKey,
+			ParentType:    node.parentType(),
+			ParentPartID:  node.ParentIDs["PART"],
+			NameRu:        node.NameRu,
+			NameKz:        node.NameKz,
+			Source:        node.source(),
+			Status:        node.Status,
+			EffectiveDate: node.EffectiveDate,
+			Active:        active,
 		})
 	case "CHAPTER":
 		data.Chapters = append(data.Chapters, models.Chapter{
 			ID:              node.ID,
+			SortKey:         node.SortKey,
+			ParentType:      node.parentType(),
 			ParentSectionID: node.ParentIDs["SECTION"],
 			ParentPartID:    node.ParentIDs["PART"],
 			NameRu:          node.NameRu,
 			NameKz:          node.NameKz,
+			Source:          node.source(),
+			Status:          node.Status,
+			EffectiveDate:   node.EffectiveDate,
+			Active:          active,
 		})
 	case "PARAGRAPH":
 		data.Paragraphs = append(data.Paragraphs, models.Paragraph{
 			ID:              node.ID,
+			SortKey:         node.SortKey,
+			ParentType:      node.parentType(),
 			ParentChapterID: node.ParentIDs["CHAPTER"],
 			ParentSectionID: node.ParentIDs["SECTION"],
 			ParentPartID:    node.ParentIDs["PART"],
 			NameRu:          node.NameRu,
 			NameKz:          node.NameKz,
+			Source:          node.source(),
+			Status:          node.Status,
+			EffectiveDate:   node.EffectiveDate,
+			Active:          active,
 		})
 	case "ARTICLE":
 		data.Articles = append(data.Articles, models.Article{
 			ID:                node.ID,
+			SortKey:           node.SortKey,
+			ParentType:        node.parentType(),
 			ParentParagraphID: node.ParentIDs["PARAGRAPH"],
 			ParentChapterID:   node.ParentIDs["CHAPTER"],
 			ParentSectionID:   node.ParentIDs["SECTION"],
 			ParentPartID:      node.ParentIDs["PART"],
 			NameRu:            node.NameRu,
 			NameKz:            node.NameKz,
+			Source:            node.source(),
+			Status:            node.Status,
+			EffectiveDate:     node.EffectiveDate,
+			Active:            active,
+			TextRu:            node.TextRu,
+			TextKz:            node.TextKz,
+		})
+	case "POINT":
+		data.Points = append(data.Points, models.Point{
+			ID:                node.ID,
+			SortKey:           node.SortKey,
+			ParentType:        node.parentType(),
+			ParentArticleID:   node.ParentIDs["ARTICLE"],
+			ParentParagraphID: node.ParentIDs["PARAGRAPH"],
+			ParentChapterID:   node.ParentIDs["CHAPTER"],
+			ParentSectionID:   node.ParentIDs["SECTION"],
+			ParentPartID:      node.ParentIDs["PART"],
+			NameRu:            node.NameRu,
+			NameKz:            node.NameKz,
+			Source:            node.source(),
+			Status:            node.Status,
+			EffectiveDate:     node.EffectiveDate,
+			Active:            active,
+			TextRu:            node.TextRu,
+			TextKz:            node.TextKz,
 		})
 	case "CLAUSE":
 		data.Clauses = append(data.Clauses, models.Clause{
 			ID:                node.ID,
+			SortKey:           node.SortKey,
+			ParentType:        node.parentType(),
+			ParentPointID:     node.ParentIDs["POINT"],
 			ParentArticleID:   node.ParentIDs["ARTICLE"],
 			ParentParagraphID: node.ParentIDs["PARAGRAPH"],
 			ParentChapterID:   node.ParentIDs["CHAPTER"],
@@ -166,11 +297,20 @@ func (p *Parser) traverseTree(node *DocumentNode, data *models.ParsedData) {
 			ParentPartID:      node.ParentIDs["PART"],
 			NameRu:            node.NameRu,
 			NameKz:            node.NameKz,
+			Source:            node.source(),
+			Status:            node.Status,
+			EffectiveDate:     node.EffectiveDate,
+			Active:            active,
+			TextRu:            node.TextRu,
+			TextKz:            node.TextKz,
 		})
 	case "SUBCLAUSE":
 		data.SubClauses = append(data.SubClauses, models.SubClause{
 			ID:                node.ID,
+			SortKey:           node.SortKey,
+			ParentType:        node.parentType(),
 			ParentClauseID:    node.ParentIDs["CLAUSE"],
+			ParentPointID:     node.ParentIDs["POINT"],
 			ParentArticleID:   node.ParentIDs["ARTICLE"],
 			ParentParagraphID: node.ParentIDs["PARAGRAPH"],
 			ParentChapterID:   node.ParentIDs["CHAPTER"],
@@ -178,18 +318,71 @@ func (p *Parser) traverseTree(node *DocumentNode, data *models.ParsedData) {
 			ParentPartID:      node.ParentIDs["PART"],
 			NameRu:            node.NameRu,
 			NameKz:            node.NameKz,
+			Source:            node.source(),
+			Status:            node.Status,
+			EffectiveDate:     node.EffectiveDate,
+			Active:            active,
+			TextRu:            node.TextRu,
+			TextKz:            node.TextKz,
 		})
 	}
 
-	for _, child := range node.Children {
-		p.traverseTree(child, data)
+	for _, note := range node.Notes {
+		for _, parsed := range parseNote(note) {
+			if node.Type != "ROOT" {
+				parsed.NodeType = node.Type
+				parsed.NodeNumber = node.ID
+			}
+			parsed.ParentArticleID = node.ParentIDs["ARTICLE"]
+			parsed.ParentParagraphID = node.ParentIDs["PARAGRAPH"]
+			parsed.ParentChapterID = node.ParentIDs["CHAPTER"]
+			parsed.ParentSectionID = node.ParentIDs["SECTION"]
+			parsed.ParentPartID = node.ParentIDs["PART"]
+			parsed.Active = active
+			data.Notes = append(data.Notes, parsed)
+		}
+	}
+}
+
+// inForce reports whether neither the node nor any of its ancestors has been
+// repealed or suspended.
+func (n *DocumentNode) inForce() bool {
+	for ; n != nil; n = n.Parent {
+		if n.Status != "" && n.Status != models.StatusActive {
+			return false
+		}
 	}
+	return true
 }
 
-func parseIntID(idStr string) int {
-	var id int
-	fmt.Sscanf(idStr, "%d", &id)
-	return id
+func (n *DocumentNode) source() models.SourcePosition {
+	return models.SourcePosition{
+		Line:    n.Line,
+		Offset:  n.Offset,
+		Page:    n.Page,
+		Anchor:  n.Anchor,
+		Heading: n.Heading,
+		Pattern: n.Pattern,
+	}
+}
+
+// parentType names the level a node hangs under directly, or "" for a
+// top-level node.
+func (n *DocumentNode) parentType() string {
+	if n.Parent == nil || n.Parent.Type == "ROOT" {
+		return ""
+	}
+	return n.Parent.Type
+}
+
+func splitNamesForLang(fullName, lang string) (string, string) {
+	switch {
+	case lang == "kz":
+		return "", strings.TrimSpace(fullName)
+	case lang == "" && !strings.Contains(fullName, "/") && isKazakhText(fullName):
+		return "", strings.TrimSpace(fullName)
+	}
+	return splitNames(fullName)
 }
 
 func splitNames(fullName string) (string, string) {
@@ -205,42 +398,23 @@ func splitNames(fullName string) (string, string) {
 	return nameRu, nameKz
 }
 
-func getParentTypes(nodeType string) []string {
-	switch nodeType {
-	case "PART":
-		return []string{}
-	case "SECTION":
-		return []string{"PART"}
-	case "CHAPTER":
-		return []string{"SECTION", "PART"}
-	case "PARAGRAPH":
-		return []string{"CHAPTER", "SECTION", "PART"}
-	case "ARTICLE":
-		return []string{"PARAGRAPH", "CHAPTER", "SECTION", "PART"}
-	case "CLAUSE":
-		return []string{"ARTICLE", "PARAGRAPH", "CHAPTER", "SECTION", "PART"}
-	case "SUBCLAUSE":
-		return []string{"CLAUSE", "ARTICLE", "PARAGRAPH", "CHAPTER", "SECTION", "PART"}
-	default:
-		return []string{}
+// isKazakhText reports whether a line uses letters that exist only in the
+// Kazakh alphabet, which is how body lines of bilingual texts are told apart.
+func isKazakhText(text string) bool {
+	for _, r := range text {
+		if isKazakhLetter(r) {
+			return true
+		}
 	}
+	return false
 }
 
-func getChildTypes(nodeType string) []string {
-	switch nodeType {
-	case "PART":
-		return []string{"SECTION", "CHAPTER", "PARAGRAPH", "ARTICLE", "CLAUSE", "SUBCLAUSE"}
-	case "SECTION":
-		return []string{"CHAPTER", "PARAGRAPH", "ARTICLE", "CLAUSE", "SUBCLAUSE"}
-	case "CHAPTER":
-		return []string{"PARAGRAPH", "ARTICLE", "CLAUSE", "SUBCLAUSE"}
-	case "PARAGRAPH":
-		return []string{"ARTICLE", "CLAUSE", "SUBCLAUSE"}
-	case "ARTICLE":
-		return []string{"CLAUSE", "SUBCLAUSE"}
-	case "CLAUSE":
-		return []string{"SUBCLAUSE"}
-	default:
-		return []string{}
+// isKazakhLetter reports the letters of the Kazakh alphabet that Russian
+// does not use.
+func isKazakhLetter(r rune) bool {
+	switch r {
+	case 'Ә', 'ә', 'Ғ', 'ғ', 'Қ', 'қ', 'Ң', 'ң', 'Ө', 'ө', 'Ұ', 'ұ', 'Ү', 'ү', 'Һ', 'һ', 'І', 'і':
+		return true
 	}
+	return false
 }
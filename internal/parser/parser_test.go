@@ -0,0 +1,326 @@
+package parser
+
+import "testing"
+
+// collectNodes gathers the nodes of a tree by type in document order. A node
+// linked to several ancestors is counted once.
+func collectNodes(node *DocumentNode, seen map[*DocumentNode]bool, nodes map[string][]*DocumentNode) {
+	for _, child := range node.Children {
+		if !seen[child] {
+			seen[child] = true
+			nodes[child.Type] = append(nodes[child.Type], child)
+			collectNodes(child, seen, nodes)
+		}
+	}
+}
+
+func TestBodyTextGoesToItsNode(t *testing.T) {
+	root := NewParser().ParseDocument(`ЧАСТЬ 1. Общая часть
+Глава 1. Общие положения
+Статья 1. Предмет регулирования
+Настоящий Кодекс регулирует отношения.
+Положения применяются к участникам.
+Статья 2. Принципы
+1) законность;
+в том числе в налоговых отношениях;
+а) по исчислению;
+разъяснение подпункта.
+Статья 3. Исполнение / Орындау
+Текст статьи.
+Бап мәтіні қазақ тілінде.`)
+	nodes := make(map[string][]*DocumentNode)
+	collectNodes(root, make(map[*DocumentNode]bool), nodes)
+
+	articles, clauses, subclauses := nodes["ARTICLE"], nodes["CLAUSE"], nodes["SUBCLAUSE"]
+	if len(articles) != 3 || len(clauses) != 1 || len(subclauses) != 1 {
+		t.Fatalf("got %d articles, %d clauses, %d subclauses", len(articles), len(clauses), len(subclauses))
+	}
+	tests := []struct {
+		node           string
+		got            *DocumentNode
+		wantRu, wantKz string
+	}{
+		{"chapter 1", nodes["CHAPTER"][0], "", ""},
+		{"article 1", articles[0], "Настоящий Кодекс регулирует отношения.\nПоложения применяются к участникам.", ""},
+		{"article 2", articles[1], "", ""},
+		{"clause 1", clauses[0], "в том числе в налоговых отношениях;", ""},
+		{"subclause а", subclauses[0], "разъяснение подпункта.", ""},
+		{"article 3", articles[2], "Текст статьи.", "Бап мәтіні қазақ тілінде."},
+	}
+	for _, tt := range tests {
+		if tt.got.TextRu != tt.wantRu || tt.got.TextKz != tt.wantKz {
+			t.Errorf("%s: text %q / %q, want %q / %q", tt.node, tt.got.TextRu, tt.got.TextKz, tt.wantRu, tt.wantKz)
+		}
+	}
+	if clauses[0].NameRu != "законность;" {
+		t.Errorf("clause 1 name %q", clauses[0].NameRu)
+	}
+}
+
+func TestKazakhHeadings(t *testing.T) {
+	root := NewParser().ParseDocument(`1-БӨЛІК. ЖАЛПЫ БӨЛІК
+2-бөлім. Салықтық әкімшілендіру
+3-тарау. Салықтар
+5-бап. Салық агенттері
+1-тармақта көзделген жағдайларда
+2) салық төлеушілер;
+ТАРАУ 4. НЕГІЗГІ ЕРЕЖЕЛЕР
+Бап 6. Салық төлеушілер
+Мәтін.
+бап бойынша`)
+	nodes := make(map[string][]*DocumentNode)
+	collectNodes(root, make(map[*DocumentNode]bool), nodes)
+
+	tests := []struct {
+		nodeType string
+		id       string
+		name     string
+	}{
+		{"PART", "1", "ЖАЛПЫ БӨЛІК"},
+		{"SECTION", "2", "Салықтық әкімшілендіру"},
+		{"CHAPTER", "3", "Салықтар"},
+		{"ARTICLE", "5", "Салық агенттері"},
+		{"CLAUSE", "2", "салық төлеушілер;"},
+		{"CHAPTER", "4", "НЕГІЗГІ ЕРЕЖЕЛЕР"},
+		{"ARTICLE", "6", "Салық төлеушілер"},
+	}
+	for _, tt := range tests {
+		if len(nodes[tt.nodeType]) == 0 {
+			t.Errorf("no %s %s %q", tt.nodeType, tt.id, tt.name)
+			continue
+		}
+		node := nodes[tt.nodeType][0]
+		nodes[tt.nodeType] = nodes[tt.nodeType][1:]
+		if node.ID != tt.id || node.NameKz != tt.name {
+			t.Errorf("%s: got %s %q, want %s %q", tt.nodeType, node.ID, node.NameKz, tt.id, tt.name)
+		}
+	}
+	for nodeType, rest := range nodes {
+		if len(rest) != 0 {
+			t.Errorf("unexpected %s nodes: %d", nodeType, len(rest))
+		}
+	}
+}
+
+func TestNumberedParagraphsArePoints(t *testing.T) {
+	root := NewParser().ParseDocument(`ЧАСТЬ 1. Общая часть
+Статья 1. Предмет
+1. Кодекс регулирует отношения.
+продолжение пункта.
+1) налоговые;
+2) бюджетные.
+2. Положения применяются к участникам.
+Статья 2. Принципы
+Вводный текст статьи.
+1. Первый пункт.`)
+	nodes := make(map[string][]*DocumentNode)
+	collectNodes(root, make(map[*DocumentNode]bool), nodes)
+
+	articles, points, clauses := nodes["ARTICLE"], nodes["POINT"], nodes["CLAUSE"]
+	if len(points) != 3 || len(clauses) != 2 {
+		t.Fatalf("got %d points and %d clauses, want 3 and 2", len(points), len(clauses))
+	}
+	if articles[0].TextRu != "" || articles[1].TextRu != "Вводный текст статьи." {
+		t.Errorf("article text %q, %q", articles[0].TextRu, articles[1].TextRu)
+	}
+
+	tests := []struct {
+		article, id, name, text string
+	}{
+		{"1", "1", "Кодекс регулирует отношения.", "продолжение пункта."},
+		{"1", "2", "Положения применяются к участникам.", ""},
+		{"2", "1", "Первый пункт.", ""},
+	}
+	for i, tt := range tests {
+		point := points[i]
+		if point.ParentIDs["ARTICLE"] != tt.article || point.ID != tt.id || point.NameRu != tt.name || point.TextRu != tt.text {
+			t.Errorf("point %d: %s/%s %q %q, want %s/%s %q %q",
+				i, point.ParentIDs["ARTICLE"], point.ID, point.NameRu, point.TextRu, tt.article, tt.id, tt.name, tt.text)
+		}
+	}
+	for _, clause := range clauses {
+		if clause.ParentIDs["POINT"] != "1" || clause.ParentIDs["ARTICLE"] != "1" || clause.TextRu != "" {
+			t.Errorf("clause %s: parent %s/%s, text %q", clause.ID, clause.ParentIDs["ARTICLE"], clause.ParentIDs["POINT"], clause.TextRu)
+		}
+	}
+}
+
+const testCode = `ЧАСТЬ 1. ОБЩАЯ ЧАСТЬ
+РАЗДЕЛ 1. ОБЩИЕ ПОЛОЖЕНИЯ
+Глава 1. ОСНОВНЫЕ ПОЛОЖЕНИЯ
+Параграф 1. Отношения, регулируемые кодексом
+Статья 1. Предмет регулирования
+1. Настоящий Кодекс регулирует отношения.
+1) налоговые;
+а) по исчислению;
+б) по уплате;
+2) бюджетные.
+2. Положения применяются к участникам.
+Глава 2. ПРИНЦИПЫ
+Статья 2. Принцип законности
+Статья 2-1. Принцип справедливости
+РАЗДЕЛ 2. УЧЕТ
+Статья 3. Учет налогоплательщиков`
+
+func TestConvertToFlatDataEmitsEachHeadingOnce(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(testCode)
+	data := p.ConvertToFlatData()
+
+	counts := map[string]int{
+		"parts":      len(data.Parts),
+		"sections":   len(data.Sections),
+		"chapters":   len(data.Chapters),
+		"paragraphs": len(data.Paragraphs),
+		"articles":   len(data.Articles),
+		"points":     len(data.Points),
+		"clauses":    len(data.Clauses),
+		"subClauses": len(data.SubClauses),
+	}
+	want := map[string]int{
+		"parts":      1,
+		"sections":   2,
+		"chapters":   2,
+		"paragraphs": 1,
+		"articles":   4,
+		"points":     2,
+		"clauses":    2,
+		"subClauses": 2,
+	}
+	for kind, n := range want {
+		if counts[kind] != n {
+			t.Errorf("%s: got %d, want %d", kind, counts[kind], n)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, article := range data.Articles {
+		key := article.ParentSectionID + "/" + article.ID
+		if seen[key] {
+			t.Errorf("article %s emitted twice", key)
+		}
+		seen[key] = true
+	}
+}
+
+func TestSkippedLevelsAttachToNearestAncestor(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(testCode)
+	data := p.ConvertToFlatData()
+
+	byID := make(map[string]int)
+	for i, article := range data.Articles {
+		byID[article.ID] = i
+	}
+
+	tests := []struct {
+		id         string
+		parentType string
+		chapter    string
+		section    string
+	}{
+		{"1", "PARAGRAPH", "1", "1"},
+		{"2", "CHAPTER", "2", "1"},
+		{"2-1", "CHAPTER", "2", "1"},
+		{"3", "SECTION", "", "2"},
+	}
+	for _, tt := range tests {
+		i, ok := byID[tt.id]
+		if !ok {
+			t.Errorf("article %s not found", tt.id)
+			continue
+		}
+		article := data.Articles[i]
+		if article.ParentType != tt.parentType {
+			t.Errorf("article %s: parent type %q, want %q", tt.id, article.ParentType, tt.parentType)
+		}
+		if article.ParentChapterID != tt.chapter || article.ParentSectionID != tt.section {
+			t.Errorf("article %s: chapter %q section %q, want %q %q",
+				tt.id, article.ParentChapterID, article.ParentSectionID, tt.chapter, tt.section)
+		}
+		if tt.parentType != "PARAGRAPH" && article.ParentParagraphID != "" {
+			t.Errorf("article %s: stale paragraph %q", tt.id, article.ParentParagraphID)
+		}
+	}
+}
+
+func TestNodesWithoutAncestorsAreTopLevel(t *testing.T) {
+	p := NewParser()
+	root := p.ParseDocument("Статья 1. Первая\n1) подпункт\nСтатья 2. Вторая")
+
+	if len(root.Children) != 2 {
+		t.Fatalf("root has %d children, want 2", len(root.Children))
+	}
+	if got := len(root.Children[0].Children); got != 1 {
+		t.Errorf("article 1 has %d children, want 1", got)
+	}
+
+	data := p.ConvertToFlatData()
+	if len(data.Articles) != 2 || len(data.Clauses) != 1 {
+		t.Fatalf("got %d articles and %d clauses, want 2 and 1", len(data.Articles), len(data.Clauses))
+	}
+	if data.Articles[0].ParentType != "" {
+		t.Errorf("top-level article has parent type %q", data.Articles[0].ParentType)
+	}
+	if data.Clauses[0].ParentType != "ARTICLE" || data.Clauses[0].ParentArticleID != "1" {
+		t.Errorf("clause parent %s %q, want ARTICLE 1", data.Clauses[0].ParentType, data.Clauses[0].ParentArticleID)
+	}
+}
+
+func TestWrappedHeadingsAreJoined(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(`Глава 1. ОБЩИЕ ПОЛОЖЕНИЯ О НАЛОГАХ
+И ДРУГИХ ОБЯЗАТЕЛЬНЫХ ПЛАТЕЖАХ
+Статья 1. Порядок исчисления налога
+на добавленную стоимость
+Настоящая статья устанавливает порядок.
+Статья 2. Сроки
+
+уплаты налога
+Статья 3. Нетто-доход.
+пояснение
+Статья 4. Особенности исчисления налога
+на добавленную стоимость
+по операциям с товарами,
+ввозимыми на территорию Республики
+Текст статьи.`)
+	data := p.ConvertToFlatData()
+
+	if got, want := data.Chapters[0].NameRu, "ОБЩИЕ ПОЛОЖЕНИЯ О НАЛОГАХ И ДРУГИХ ОБЯЗАТЕЛЬНЫХ ПЛАТЕЖАХ"; got != want {
+		t.Errorf("chapter name %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"Порядок исчисления налога на добавленную стоимость", "Настоящая статья устанавливает порядок."},
+		{"Сроки", "уплаты налога"},
+		{"Нетто-доход.", "пояснение"},
+		{"Особенности исчисления налога на добавленную стоимость по операциям с товарами, ввозимыми на территорию Республики", "Текст статьи."},
+	}
+	if len(data.Articles) != len(tests) {
+		t.Fatalf("got %d articles, want %d", len(data.Articles), len(tests))
+	}
+	for i, tt := range tests {
+		if data.Articles[i].NameRu != tt.name || data.Articles[i].TextRu != tt.text {
+			t.Errorf("article %d: name %q text %q, want %q %q",
+				i+1, data.Articles[i].NameRu, data.Articles[i].TextRu, tt.name, tt.text)
+		}
+	}
+}
+
+func TestRequiredLevelEnclosesLowerLevels(t *testing.T) {
+	p := NewParser()
+	root := p.ParseDocument("Настоящий Кодекс:\n1) устанавливает налоги;\n2) определяет порядок.\nСтатья 1. Предмет\n1) налоговые отношения;")
+
+	if len(root.Children) != 1 || root.Children[0].Type != "ARTICLE" {
+		t.Fatalf("root children = %d, first %+v", len(root.Children), root.Children[0])
+	}
+	if got := len(root.Children[0].Children); got != 1 {
+		t.Errorf("article 1 has %d children, want 1", got)
+	}
+	if got := len(p.preamble); got != 3 {
+		t.Errorf("preamble has %d lines, want 3", got)
+	}
+}
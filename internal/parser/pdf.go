@@ -0,0 +1,550 @@
+package parser
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+type pdfName string
+type pdfString string
+type pdfKeyword string
+type pdfArray []interface{}
+type pdfDict map[string]interface{}
+
+type pdfRef struct {
+	num int
+	gen int
+}
+
+type pdfStream struct {
+	dict pdfDict
+	data []byte
+}
+
+type pdfFile struct {
+	objects  map[int]interface{}
+	trailers []pdfDict
+}
+
+var pdfObjectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// parsePDF locates every "N G obj" in the file instead of trusting the xref
+// table, which is often broken in documents produced by older converters.
+// Later definitions win, matching incremental update semantics.
+func parsePDF(data []byte) (*pdfFile, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, fmt.Errorf("error reading pdf: missing %%PDF header")
+	}
+
+	f := &pdfFile{objects: make(map[int]interface{})}
+	pos := 0
+	for pos < len(data) {
+		loc := pdfObjectHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		lex := &pdfLexer{data: data, pos: pos + loc[1]}
+		object := lex.readObject()
+		lex.skipSpace()
+		if dict, ok := object.(pdfDict); ok && lex.hasKeyword("stream") {
+			object = &pdfStream{dict: dict, data: lex.readStreamData(dict)}
+		}
+		f.objects[num] = object
+		pos = lex.pos
+	}
+
+	for idx := 0; ; {
+		found := bytes.Index(data[idx:], []byte("trailer"))
+		if found < 0 {
+			break
+		}
+		lex := &pdfLexer{data: data, pos: idx + found + len("trailer")}
+		if dict, ok := lex.readObject().(pdfDict); ok {
+			f.trailers = append(f.trailers, dict)
+		}
+		idx = lex.pos
+	}
+
+	for _, object := range f.objects {
+		stream, ok := object.(*pdfStream)
+		if !ok {
+			continue
+		}
+		switch stream.dict["Type"] {
+		case pdfName("XRef"):
+			f.trailers = append(f.trailers, stream.dict)
+		case pdfName("ObjStm"):
+			f.loadObjectStream(stream)
+		}
+	}
+
+	for _, trailer := range f.trailers {
+		if _, ok := trailer["Encrypt"]; ok {
+			return nil, fmt.Errorf("error reading pdf: document is encrypted")
+		}
+	}
+
+	return f, nil
+}
+
+func (f *pdfFile) loadObjectStream(stream *pdfStream) {
+	data, err := f.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	count, _ := f.resolve(stream.dict["N"]).(int)
+	first, _ := f.resolve(stream.dict["First"]).(int)
+
+	lex := &pdfLexer{data: data}
+	for i := 0; i < count; i++ {
+		num, ok1 := lex.readObject().(int)
+		offset, ok2 := lex.readObject().(int)
+		if !ok1 || !ok2 {
+			return
+		}
+		if _, exists := f.objects[num]; exists {
+			continue
+		}
+		// Offsets come from the file; an object outside the stream is
+		// dropped rather than read from a bad position.
+		pos := first + offset
+		if first < 0 || offset < 0 || pos >= len(data) {
+			continue
+		}
+		objectLex := &pdfLexer{data: data, pos: pos}
+		f.objects[num] = objectLex.readObject()
+	}
+}
+
+func (f *pdfFile) resolve(v interface{}) interface{} {
+	for depth := 0; depth < 32; depth++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(v interface{}) pdfDict {
+	switch t := f.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.dict
+	}
+	return nil
+}
+
+func (f *pdfFile) array(v interface{}) pdfArray {
+	a, _ := f.resolve(v).(pdfArray)
+	return a
+}
+
+func (f *pdfFile) number(v interface{}) (float64, bool) {
+	switch t := f.resolve(v).(type) {
+	case int:
+		return float64(t), true
+	case float64:
+		return t, true
+	}
+	return 0, false
+}
+
+func (f *pdfFile) root() pdfDict {
+	for i := len(f.trailers) - 1; i >= 0; i-- {
+		if root := f.dict(f.trailers[i]["Root"]); root != nil {
+			return root
+		}
+	}
+	for _, object := range f.objects {
+		if dict, ok := object.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+			return dict
+		}
+	}
+	return nil
+}
+
+// pages walks the page tree so that pages come out in document order and
+// inherit resources from their ancestors.
+func (f *pdfFile) pages() []pdfDict {
+	var pages []pdfDict
+	root := f.root()
+	if root == nil {
+		return pages
+	}
+
+	var walk func(node pdfDict, resources interface{}, depth int)
+	walk = func(node pdfDict, resources interface{}, depth int) {
+		if node == nil || depth > 64 {
+			return
+		}
+		if r, ok := node["Resources"]; ok {
+			resources = r
+		}
+		if node["Type"] == pdfName("Page") || node["Kids"] == nil {
+			page := make(pdfDict, len(node)+1)
+			for k, v := range node {
+				page[k] = v
+			}
+			page["Resources"] = resources
+			pages = append(pages, page)
+			return
+		}
+		for _, kid := range f.array(node["Kids"]) {
+			walk(f.dict(kid), resources, depth+1)
+		}
+	}
+	walk(f.dict(root["Pages"]), nil, 0)
+
+	return pages
+}
+
+func (f *pdfFile) decodeStream(stream *pdfStream) ([]byte, error) {
+	var filters pdfArray
+	switch t := f.resolve(stream.dict["Filter"]).(type) {
+	case pdfName:
+		filters = pdfArray{t}
+	case pdfArray:
+		filters = t
+	}
+
+	data := stream.data
+	for _, filter := range filters {
+		var err error
+		switch f.resolve(filter) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			data, err = inflate(data)
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			data, err = decodeASCIIHex(data)
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			err = fmt.Errorf("unsupported filter %v", filter)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error decoding pdf stream: %v", err)
+		}
+	}
+
+	return data, nil
+}
+
+// maxPDFStreamSize bounds what one stream may inflate to, so that a small
+// compressed stream cannot exhaust memory.
+const maxPDFStreamSize = 64 << 20
+
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, maxPDFStreamSize+1))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	if len(out) > maxPDFStreamSize {
+		return nil, fmt.Errorf("stream inflates to more than %d bytes", maxPDFStreamSize)
+	}
+	// Truncated or padded streams are common; keep whatever inflated.
+	return out, nil
+}
+
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	clean := make([]byte, 0, len(data))
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if isPDFSpace(c) {
+			continue
+		}
+		clean = append(clean, c)
+	}
+	if len(clean)%2 == 1 {
+		clean = append(clean, '0')
+	}
+	return hex.DecodeString(string(clean))
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if end := bytes.Index(data, []byte("~>")); end >= 0 {
+		data = data[:end]
+	}
+	out := make([]byte, len(data)*4/5+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	return out[:n], err
+}
+
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return c == '(' || c == ')' || c == '<' || c == '>' || c == '[' || c == ']' ||
+		c == '{' || c == '}' || c == '/' || c == '%'
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+func (l *pdfLexer) hasKeyword(keyword string) bool {
+	if !bytes.HasPrefix(l.data[l.pos:], []byte(keyword)) {
+		return false
+	}
+	l.pos += len(keyword)
+	return true
+}
+
+func (l *pdfLexer) readStreamData(dict pdfDict) []byte {
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+	start := l.pos
+
+	if length, ok := dict["Length"].(int); ok && length >= 0 && start+length <= len(l.data) {
+		rest := bytes.TrimLeft(l.data[start+length:], " \t\r\n")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			l.pos = start + length
+			return l.data[start : start+length]
+		}
+	}
+
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		l.pos = len(l.data)
+		return l.data[start:]
+	}
+	l.pos = start + end + len("endstream")
+	return bytes.TrimRight(l.data[start:start+end], "\r\n")
+}
+
+// readObject returns the next object; operators in content streams come
+// back as pdfKeyword and the end of input as nil.
+func (l *pdfLexer) readObject() interface{} {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		return l.readName()
+	case c == '(':
+		return l.readLiteralString()
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		return l.readDict()
+	case c == '<':
+		return l.readHexString()
+	case c == '[':
+		l.pos++
+		var array pdfArray
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return array
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return array
+			}
+			array = append(array, l.readObject())
+		}
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.readNumberOrRef()
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword(c)
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		l.pos++
+	}
+	switch word := string(l.data[start:l.pos]); word {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	default:
+		return pdfKeyword(word)
+	}
+}
+
+func (l *pdfLexer) readName() pdfName {
+	l.pos++
+	var name []byte
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		c := l.data[l.pos]
+		if c == '#' && l.pos+2 < len(l.data) {
+			if value, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				name = append(name, byte(value))
+				l.pos += 3
+				continue
+			}
+		}
+		name = append(name, c)
+		l.pos++
+	}
+	return pdfName(name)
+}
+
+func (l *pdfLexer) readDict() pdfDict {
+	l.pos += 2
+	dict := make(pdfDict)
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return dict
+		}
+		if l.data[l.pos] == '>' {
+			l.pos += 2
+			return dict
+		}
+		key, ok := l.readObject().(pdfName)
+		if !ok {
+			continue
+		}
+		dict[string(key)] = l.readObject()
+	}
+}
+
+func (l *pdfLexer) readLiteralString() pdfString {
+	l.pos++
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(out)
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return pdfString(out)
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					value := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						value = value*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(value))
+				} else {
+					out = append(out, e)
+				}
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return pdfString(out)
+}
+
+func (l *pdfLexer) readHexString() pdfString {
+	l.pos++
+	start := l.pos
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		l.pos++
+	}
+	decoded, _ := decodeASCIIHex(l.data[start:l.pos])
+	l.pos++
+	return pdfString(decoded)
+}
+
+func (l *pdfLexer) readNumberOrRef() interface{} {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.data) && (l.data[l.pos] == '.' || (l.data[l.pos] >= '0' && l.data[l.pos] <= '9')) {
+		l.pos++
+	}
+	text := string(l.data[start:l.pos])
+
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		f, _ := strconv.ParseFloat(text, 64)
+		return f
+	}
+
+	// "12 0 R" is an indirect reference; peek ahead without consuming
+	// anything if the pattern does not match.
+	save := l.pos
+	l.skipSpace()
+	genStart := l.pos
+	for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		l.pos++
+	}
+	if l.pos > genStart {
+		gen, _ := strconv.Atoi(string(l.data[genStart:l.pos]))
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+			(l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelimiter(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{num: value, gen: gen}
+		}
+	}
+	l.pos = save
+	return value
+}
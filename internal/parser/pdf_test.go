@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+)
+
+func TestPDFObjectStreamBadOffsets(t *testing.T) {
+	f := &pdfFile{objects: make(map[int]interface{})}
+	stream := &pdfStream{
+		dict: pdfDict{"N": 3, "First": 16},
+		data: []byte("1 0 2 -20 3 999 (text)"),
+	}
+	f.loadObjectStream(stream)
+
+	if got, ok := f.objects[1].(pdfString); !ok || got != "text" {
+		t.Errorf("object 1 = %#v", f.objects[1])
+	}
+	if _, ok := f.objects[2]; ok {
+		t.Error("object with a negative offset was loaded")
+	}
+	if _, ok := f.objects[3]; ok {
+		t.Error("object past the end of the stream was loaded")
+	}
+}
+
+func TestInflateLimit(t *testing.T) {
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	w.Write(make([]byte, maxPDFStreamSize+1))
+	w.Close()
+
+	if _, err := inflate(compressed.Bytes()); err == nil {
+		t.Error("oversized stream inflated without error")
+	}
+}
+
+func TestPDFCIDWidthsRange(t *testing.T) {
+	widths := make(map[int]float64)
+	f := &pdfFile{objects: make(map[int]interface{})}
+	loadPDFCIDWidths(f, pdfArray{0, 2147483647, 500, 10, 12, 600}, widths)
+
+	if len(widths) != 3 || widths[11] != 600 {
+		t.Errorf("widths = %v", widths)
+	}
+}
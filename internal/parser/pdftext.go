@@ -0,0 +1,768 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	pdfHeaderFooterDepth = 2
+	pdfMaxFormDepth      = 8
+)
+
+var (
+	pdfPageNumberLine = regexp.MustCompile(`(?i)^(?:[-–—\s]*\d+[-–—\s]*|(?:стр\.?|страница|бет|page)\s*\d+(?:\s*(?:из|of)\s*\d+)?)$`)
+	pdfDigits         = regexp.MustCompile(`\d+`)
+)
+
+var pdfGlyphNames = map[string]rune{
+	"space": ' ', "period": '.', "comma": ',', "colon": ':', "semicolon": ';',
+	"hyphen": '-', "parenleft": '(', "parenright": ')', "quotedbl": '"',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4',
+	"five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+	"endash": '–', "emdash": '—', "guillemotleft": '«', "guillemotright": '»',
+	"numero": '№', "afii61352": '№',
+}
+
+func readPDF(filePath string) (*Source, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading pdf: %v", err)
+	}
+
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := f.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("error reading pdf: no pages found")
+	}
+
+	pageLines := make([][]pdfTextLine, 0, len(pages))
+	for _, page := range pages {
+		extractor := &pdfExtractor{file: f, fonts: make(map[string]*pdfFont)}
+		extractor.runPage(page)
+		pageLines = append(pageLines, layoutPDFLines(extractor.spans))
+	}
+
+	lines := stripPDFHeadersAndFooters(pageLines)
+	return &Source{Lines: joinPDFHyphenation(lines)}, nil
+}
+
+type pdfSpan struct {
+	text string
+	x    float64
+	endX float64
+	y    float64
+	size float64
+}
+
+type pdfTextLine struct {
+	text string
+	y    float64
+	size float64
+}
+
+type pdfMatrix [6]float64
+
+var pdfIdentity = pdfMatrix{1, 0, 0, 1, 0, 0}
+
+func (m pdfMatrix) multiply(n pdfMatrix) pdfMatrix {
+	return pdfMatrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func (m pdfMatrix) point(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+type pdfGraphicsState struct {
+	ctm      pdfMatrix
+	font     *pdfFont
+	fontSize float64
+	charSp   float64
+	wordSp   float64
+	scale    float64
+	leading  float64
+}
+
+type pdfExtractor struct {
+	file  *pdfFile
+	fonts map[string]*pdfFont
+	spans []pdfSpan
+
+	state pdfGraphicsState
+	stack []pdfGraphicsState
+	tm    pdfMatrix
+	tlm   pdfMatrix
+}
+
+func (e *pdfExtractor) runPage(page pdfDict) {
+	var content []byte
+	contents := e.file.resolve(page["Contents"])
+	streams := pdfArray{contents}
+	if array, ok := contents.(pdfArray); ok {
+		streams = array
+	}
+	for _, item := range streams {
+		stream, ok := e.file.resolve(item).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := e.file.decodeStream(stream)
+		if err != nil {
+			continue
+		}
+		content = append(content, data...)
+		content = append(content, '\n')
+	}
+
+	e.state = pdfGraphicsState{ctm: pdfIdentity, scale: 1}
+	e.run(content, e.file.dict(page["Resources"]), 0)
+}
+
+func (e *pdfExtractor) run(content []byte, resources pdfDict, depth int) {
+	lex := &pdfLexer{data: content}
+	var operands []interface{}
+
+	for {
+		object := lex.readObject()
+		if object == nil && lex.pos >= len(lex.data) {
+			return
+		}
+		op, ok := object.(pdfKeyword)
+		if !ok {
+			operands = append(operands, object)
+			continue
+		}
+
+		if op == "BI" {
+			e.skipInlineImage(lex)
+		} else {
+			e.apply(string(op), operands, resources, depth)
+		}
+		operands = operands[:0]
+	}
+}
+
+func (e *pdfExtractor) skipInlineImage(lex *pdfLexer) {
+	end := strings.Index(string(lex.data[lex.pos:]), "EI")
+	for end >= 0 {
+		next := lex.pos + end + 2
+		if next >= len(lex.data) || isPDFSpace(lex.data[next]) {
+			lex.pos = next
+			return
+		}
+		more := strings.Index(string(lex.data[next:]), "EI")
+		if more < 0 {
+			break
+		}
+		end = next - lex.pos + more
+	}
+	lex.pos = len(lex.data)
+}
+
+func (e *pdfExtractor) apply(op string, operands []interface{}, resources pdfDict, depth int) {
+	num := func(i int) float64 {
+		if i >= len(operands) {
+			return 0
+		}
+		value, _ := e.file.number(operands[i])
+		return value
+	}
+
+	switch op {
+	case "q":
+		e.stack = append(e.stack, e.state)
+	case "Q":
+		if len(e.stack) > 0 {
+			e.state = e.stack[len(e.stack)-1]
+			e.stack = e.stack[:len(e.stack)-1]
+		}
+	case "cm":
+		if len(operands) == 6 {
+			m := pdfMatrix{num(0), num(1), num(2), num(3), num(4), num(5)}
+			e.state.ctm = m.multiply(e.state.ctm)
+		}
+	case "BT":
+		e.tm = pdfIdentity
+		e.tlm = pdfIdentity
+	case "Tf":
+		if len(operands) == 2 {
+			name, _ := operands[0].(pdfName)
+			e.state.font = e.font(string(name), resources)
+			e.state.fontSize = num(1)
+		}
+	case "Tc":
+		e.state.charSp = num(0)
+	case "Tw":
+		e.state.wordSp = num(0)
+	case "Tz":
+		e.state.scale = num(0) / 100
+	case "TL":
+		e.state.leading = num(0)
+	case "Td":
+		e.moveLine(num(0), num(1))
+	case "TD":
+		e.state.leading = -num(1)
+		e.moveLine(num(0), num(1))
+	case "Tm":
+		if len(operands) == 6 {
+			e.tm = pdfMatrix{num(0), num(1), num(2), num(3), num(4), num(5)}
+			e.tlm = e.tm
+		}
+	case "T*":
+		e.moveLine(0, -e.state.leading)
+	case "Tj":
+		if len(operands) == 1 {
+			e.show(operands[0])
+		}
+	case "'":
+		e.moveLine(0, -e.state.leading)
+		if len(operands) == 1 {
+			e.show(operands[0])
+		}
+	case "\"":
+		if len(operands) == 3 {
+			e.state.wordSp = num(0)
+			e.state.charSp = num(1)
+			e.moveLine(0, -e.state.leading)
+			e.show(operands[2])
+		}
+	case "TJ":
+		if len(operands) != 1 {
+			return
+		}
+		array, _ := operands[0].(pdfArray)
+		for _, item := range array {
+			if value, ok := e.file.number(item); ok {
+				tx := -value / 1000 * e.state.fontSize * e.state.scale
+				e.tm = pdfMatrix{1, 0, 0, 1, tx, 0}.multiply(e.tm)
+				continue
+			}
+			e.show(item)
+		}
+	case "Do":
+		if len(operands) == 1 && depth < pdfMaxFormDepth {
+			name, _ := operands[0].(pdfName)
+			e.runForm(string(name), resources, depth)
+		}
+	}
+}
+
+func (e *pdfExtractor) moveLine(tx, ty float64) {
+	e.tlm = pdfMatrix{1, 0, 0, 1, tx, ty}.multiply(e.tlm)
+	e.tm = e.tlm
+}
+
+func (e *pdfExtractor) runForm(name string, resources pdfDict, depth int) {
+	xobjects := e.file.dict(resources["XObject"])
+	stream, ok := e.file.resolve(xobjects[name]).(*pdfStream)
+	if !ok || stream.dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	data, err := e.file.decodeStream(stream)
+	if err != nil {
+		return
+	}
+
+	formResources := e.file.dict(stream.dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+
+	saved, savedTm, savedTlm := e.state, e.tm, e.tlm
+	if m := e.file.array(stream.dict["Matrix"]); len(m) == 6 {
+		var matrix pdfMatrix
+		for i := range matrix {
+			matrix[i], _ = e.file.number(m[i])
+		}
+		e.state.ctm = matrix.multiply(e.state.ctm)
+	}
+	e.run(data, formResources, depth+1)
+	e.state, e.tm, e.tlm = saved, savedTm, savedTlm
+}
+
+func (e *pdfExtractor) show(operand interface{}) {
+	raw, ok := e.file.resolve(operand).(pdfString)
+	if !ok || e.state.font == nil {
+		return
+	}
+
+	trm := e.tm.multiply(e.state.ctm)
+	x, y := trm.point(0, 0)
+	size := e.state.fontSize * math.Hypot(trm[2], trm[3])
+
+	var text strings.Builder
+	for _, glyph := range e.state.font.decode(string(raw)) {
+		text.WriteString(glyph.text)
+		tx := glyph.width*e.state.fontSize + e.state.charSp
+		if glyph.space {
+			tx += e.state.wordSp
+		}
+		tx *= e.state.scale
+		e.tm = pdfMatrix{1, 0, 0, 1, tx, 0}.multiply(e.tm)
+	}
+
+	endX, _ := e.tm.multiply(e.state.ctm).point(0, 0)
+	if text.Len() > 0 {
+		e.spans = append(e.spans, pdfSpan{text: text.String(), x: x, endX: endX, y: y, size: size})
+	}
+}
+
+func (e *pdfExtractor) font(name string, resources pdfDict) *pdfFont {
+	fonts := e.file.dict(resources["Font"])
+	ref := fonts[name]
+	key := name
+	if r, ok := ref.(pdfRef); ok {
+		key = strconv.Itoa(r.num)
+	}
+	if font, ok := e.fonts[key]; ok {
+		return font
+	}
+
+	font := loadPDFFont(e.file, e.file.dict(ref))
+	e.fonts[key] = font
+	return font
+}
+
+// layoutPDFLines groups spans that share a baseline into lines, top to
+// bottom, and inserts spaces where the gap between spans implies one. A
+// blank line marks a vertical gap wider than the usual leading.
+func layoutPDFLines(spans []pdfSpan) []pdfTextLine {
+	sort.SliceStable(spans, func(i, j int) bool {
+		if math.Abs(spans[i].y-spans[j].y) > 0.01 {
+			return spans[i].y > spans[j].y
+		}
+		return spans[i].x < spans[j].x
+	})
+
+	var groups [][]pdfSpan
+	for _, span := range spans {
+		if n := len(groups); n > 0 {
+			last := groups[n-1]
+			tolerance := math.Max(math.Min(last[0].size, span.size)*0.5, 1)
+			if math.Abs(last[0].y-span.y) <= tolerance {
+				groups[n-1] = append(last, span)
+				continue
+			}
+		}
+		groups = append(groups, []pdfSpan{span})
+	}
+
+	var lines []pdfTextLine
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].x < group[j].x })
+
+		var text strings.Builder
+		size := 0.0
+		for i, span := range group {
+			if i > 0 {
+				prev := group[i-1]
+				gap := span.x - prev.endX
+				if gap > span.size*0.2 && !strings.HasSuffix(prev.text, " ") && !strings.HasPrefix(span.text, " ") {
+					text.WriteByte(' ')
+				}
+			}
+			text.WriteString(span.text)
+			size = math.Max(size, span.size)
+		}
+
+		line := pdfTextLine{text: strings.Join(strings.Fields(text.String()), " "), y: group[0].y, size: size}
+		if line.text == "" {
+			continue
+		}
+		if n := len(lines); n > 0 && lines[n-1].y-line.y > math.Max(lines[n-1].size, size)*1.8 {
+			lines = append(lines, pdfTextLine{y: line.y})
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// stripPDFHeadersAndFooters drops the lines at the top and bottom of each
+// page that are page numbers or that repeat (digits aside) on at least half
+// of the pages.
+func stripPDFHeadersAndFooters(pages [][]pdfTextLine) []Line {
+	edgeKey := func(text string) string {
+		return strings.ToLower(pdfDigits.ReplaceAllString(text, "#"))
+	}
+	isEdge := func(lines []pdfTextLine, i int) bool {
+		return edgeIndex(lines, i) < pdfHeaderFooterDepth
+	}
+
+	counts := make(map[string]int)
+	for _, lines := range pages {
+		seen := make(map[string]bool)
+		for i, line := range lines {
+			if line.text == "" || !isEdge(lines, i) {
+				continue
+			}
+			key := edgeKey(line.text)
+			if !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+	}
+
+	threshold := len(pages) / 2
+	if threshold < 2 {
+		threshold = 2
+	}
+
+	var out []Line
+	for pageIndex, lines := range pages {
+		for i, line := range lines {
+			if line.text != "" && isEdge(lines, i) {
+				if pdfPageNumberLine.MatchString(line.text) || counts[edgeKey(line.text)] >= threshold {
+					continue
+				}
+			}
+			out = append(out, Line{Text: line.text, Page: pageIndex + 1})
+		}
+	}
+
+	return out
+}
+
+// edgeIndex is the distance of a line from the nearest page edge, counting
+// only non-blank lines.
+func edgeIndex(lines []pdfTextLine, i int) int {
+	fromTop, fromBottom := 0, 0
+	for j := 0; j < i; j++ {
+		if lines[j].text != "" {
+			fromTop++
+		}
+	}
+	for j := len(lines) - 1; j > i; j-- {
+		if lines[j].text != "" {
+			fromBottom++
+		}
+	}
+	if fromTop < fromBottom {
+		return fromTop
+	}
+	return fromBottom
+}
+
+// joinPDFHyphenation moves the tail of a word hyphenated across a line
+// break back onto the line where the word started.
+func joinPDFHyphenation(lines []Line) []Line {
+	out := make([]Line, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		for {
+			text := strings.TrimSuffix(strings.TrimSuffix(line.Text, "\u00ad"), "-")
+			if text == line.Text || i+1 >= len(lines) {
+				break
+			}
+			last, _ := utf8.DecodeLastRuneInString(text)
+			next := lines[i+1].Text
+			first, _ := utf8.DecodeRuneInString(next)
+			if !unicode.IsLetter(last) || !unicode.IsLower(first) {
+				break
+			}
+
+			word, rest, _ := strings.Cut(next, " ")
+			line.Text = text + word
+			lines[i+1].Text = rest
+			if rest != "" {
+				break
+			}
+			i++
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+type pdfGlyph struct {
+	text  string
+	width float64
+	space bool
+}
+
+type pdfFont struct {
+	twoByte      bool
+	toUnicode    map[int]string
+	encoding     map[int]rune
+	widths       map[int]float64
+	defaultWidth float64
+}
+
+func (font *pdfFont) decode(raw string) []pdfGlyph {
+	step := 1
+	if font.twoByte {
+		step = 2
+	}
+
+	glyphs := make([]pdfGlyph, 0, len(raw)/step)
+	for i := 0; i+step <= len(raw); i += step {
+		code := int(raw[i])
+		if step == 2 {
+			code = code<<8 | int(raw[i+1])
+		}
+
+		text, ok := font.toUnicode[code]
+		if !ok {
+			if r, found := font.encoding[code]; found {
+				text = string(r)
+			} else if !font.twoByte {
+				text = decodeCodepage([]byte{byte(code)}, 1252)
+			}
+		}
+
+		width, ok := font.widths[code]
+		if !ok {
+			width = font.defaultWidth
+		}
+		glyphs = append(glyphs, pdfGlyph{text: text, width: width / 1000, space: step == 1 && code == 32})
+	}
+	return glyphs
+}
+
+func loadPDFFont(f *pdfFile, dict pdfDict) *pdfFont {
+	font := &pdfFont{
+		toUnicode:    make(map[int]string),
+		encoding:     make(map[int]rune),
+		widths:       make(map[int]float64),
+		defaultWidth: 500,
+	}
+	if dict == nil {
+		return font
+	}
+
+	if dict["Subtype"] == pdfName("Type0") {
+		font.twoByte = true
+		font.defaultWidth = 1000
+		descendants := f.array(dict["DescendantFonts"])
+		if len(descendants) > 0 {
+			cidFont := f.dict(descendants[0])
+			if dw, ok := f.number(cidFont["DW"]); ok {
+				font.defaultWidth = dw
+			}
+			loadPDFCIDWidths(f, f.array(cidFont["W"]), font.widths)
+		}
+	} else {
+		first, _ := f.number(dict["FirstChar"])
+		for i, w := range f.array(dict["Widths"]) {
+			if width, ok := f.number(w); ok {
+				font.widths[int(first)+i] = width
+			}
+		}
+		if descriptor := f.dict(dict["FontDescriptor"]); descriptor != nil {
+			if missing, ok := f.number(descriptor["MissingWidth"]); ok && missing > 0 {
+				font.defaultWidth = missing
+			}
+		}
+		loadPDFEncoding(f, f.resolve(dict["Encoding"]), font.encoding)
+	}
+
+	if stream, ok := f.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := f.decodeStream(stream); err == nil {
+			parsePDFCMap(data, font)
+		}
+	}
+
+	return font
+}
+
+// maxPDFCIDRange bounds one "first last width" range of a /W array: CIDs
+// are 16-bit, so a wider range can only be a broken or hostile file.
+const maxPDFCIDRange = 65535
+
+func loadPDFCIDWidths(f *pdfFile, w pdfArray, widths map[int]float64) {
+	for i := 0; i < len(w); {
+		first, ok := f.number(w[i])
+		if !ok || i+1 >= len(w) {
+			return
+		}
+		if list := f.array(w[i+1]); list != nil {
+			for j, item := range list {
+				if width, ok := f.number(item); ok {
+					widths[int(first)+j] = width
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		last, _ := f.number(w[i+1])
+		width, _ := f.number(w[i+2])
+		if first < 0 || last-first > maxPDFCIDRange {
+			i += 3
+			continue
+		}
+		for code := int(first); code <= int(last); code++ {
+			widths[code] = width
+		}
+		i += 3
+	}
+}
+
+func loadPDFEncoding(f *pdfFile, encoding interface{}, out map[int]rune) {
+	base := encoding
+	var differences pdfArray
+	if dict, ok := encoding.(pdfDict); ok {
+		base = dict["BaseEncoding"]
+		differences = f.array(dict["Differences"])
+	}
+
+	if base == pdfName("MacRomanEncoding") {
+		for code := 128; code < 256; code++ {
+			decoded := []rune(decodeCodepage([]byte{byte(code)}, 10000))
+			if len(decoded) == 1 {
+				out[code] = decoded[0]
+			}
+		}
+	}
+
+	code := 0
+	for _, item := range differences {
+		switch t := f.resolve(item).(type) {
+		case int:
+			code = t
+		case pdfName:
+			if r, ok := pdfGlyphRune(string(t)); ok {
+				out[code] = r
+			}
+			code++
+		}
+	}
+}
+
+// pdfGlyphRune covers the glyph names that show up in Cyrillic documents
+// without a ToUnicode map: uniXXXX, uXXXX, the afii100xx Cyrillic range and
+// single ASCII letters.
+func pdfGlyphRune(name string) (rune, bool) {
+	switch {
+	case strings.HasPrefix(name, "uni") && len(name) == 7:
+		value, err := strconv.ParseUint(name[3:], 16, 32)
+		return rune(value), err == nil
+	case strings.HasPrefix(name, "u") && len(name) >= 5 && len(name) <= 7:
+		value, err := strconv.ParseUint(name[1:], 16, 32)
+		return rune(value), err == nil
+	case strings.HasPrefix(name, "afii100"):
+		value, err := strconv.Atoi(name[4:])
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case value == 10023:
+			return 'Ё', true
+		case value == 10071:
+			return 'ё', true
+		case value >= 10017 && value <= 10022:
+			return rune(0x0410 + value - 10017), true
+		case value >= 10024 && value <= 10049:
+			return rune(0x0416 + value - 10024), true
+		case value >= 10065 && value <= 10070:
+			return rune(0x0430 + value - 10065), true
+		case value >= 10072 && value <= 10097:
+			return rune(0x0436 + value - 10072), true
+		}
+	case len(name) == 1:
+		return rune(name[0]), true
+	}
+
+	r, ok := pdfGlyphNames[name]
+	return r, ok
+}
+
+func parsePDFCMap(data []byte, font *pdfFont) {
+	lex := &pdfLexer{data: data}
+	var operands []interface{}
+
+	for {
+		object := lex.readObject()
+		if object == nil && lex.pos >= len(lex.data) {
+			return
+		}
+		op, ok := object.(pdfKeyword)
+		if !ok {
+			operands = append(operands, object)
+			continue
+		}
+
+		switch op {
+		case "endcodespacerange":
+			if len(operands) >= 1 {
+				if low, ok := operands[0].(pdfString); ok {
+					font.twoByte = len(low) == 2
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					font.toUnicode[pdfCode(src)] = decodeUTF16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok1 := operands[i].(pdfString)
+				high, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 {
+					continue
+				}
+				start, end := pdfCode(low), pdfCode(high)
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					base := []rune(decodeUTF16BE(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for code := start; code <= end && code-start < 0x10000; code++ {
+						mapped := append([]rune{}, base...)
+						mapped[len(mapped)-1] += rune(code - start)
+						font.toUnicode[code] = string(mapped)
+					}
+				case pdfArray:
+					for j, item := range dst {
+						if s, ok := item.(pdfString); ok {
+							font.toUnicode[start+j] = decodeUTF16BE(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+func pdfCode(s pdfString) int {
+	code := 0
+	for i := 0; i < len(s); i++ {
+		code = code<<8 | int(s[i])
+	}
+	return code
+}
+
+func decodeUTF16BE(s pdfString) string {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,371 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// Russian references name the level before its number and go from the
+// finest level to the coarsest: "подпунктом 3) пункта 2 статьи 40".
+var ruReferenceRegex = regexp.MustCompile(`(?i)(?:^|[^\p{L}])(подпункт|пункт|стать|глав|параграф|раздел)\p{L}*\s+(\d+(?:-\d+)*\)?(?:\s*(?:,|и|или)\s*\d+(?:-\d+)*\)?)*)`)
+
+// Kazakh references put the number first and go the other way round:
+// "40-баптың 2-тармағының 3) тармақшасы".
+var kzReferenceRegex = regexp.MustCompile(`(?i)((?:\d+(?:-\d+)*\)?\s*(?:,|және|немесе)\s*)*\d+(?:-\d+)*(?:\)|-))\s*(тармақша|тармақ|тармағ|бап|баб|тарау|параграф|бөлім)\p{L}*`)
+
+var referenceNumberRegex = regexp.MustCompile(`\d+(?:-\d+)*`)
+
+var referenceLevels = map[string]string{
+	"подпункт": "CLAUSE",
+	"пункт":    "POINT",
+	"стать":    "ARTICLE",
+	"глав":     "CHAPTER",
+	"параграф": "PARAGRAPH",
+	"раздел":   "SECTION",
+	"тармақша": "CLAUSE",
+	"тармақ":   "POINT",
+	"тармағ":   "POINT",
+	"бап":      "ARTICLE",
+	"баб":      "ARTICLE",
+	"тарау":    "CHAPTER",
+	"бөлім":    "SECTION",
+}
+
+// Qualifiers that follow a Russian reference or precede a Kazakh one.
+var (
+	ruRelativeArticle = regexp.MustCompile(`^(?i:настоящей\s+статьи)`)
+	ruRelativePoint   = regexp.MustCompile(`^(?i:настоящего\s+пункта)`)
+	ruThisAct         = regexp.MustCompile(`^(?i:настоящ\p{L}*\s+\p{L}+)`)
+	ruExternalAct     = regexp.MustCompile(`^(?i:((?:[\p{L}-]+\s+){0,2}?(?:кодекса|закона|указа|постановления|конституции|приказа)(?:\s+республики\s+казахстан)?(?:\s+«[^»]*»)?)(?:[^\p{L}]|$))`)
+	kzRelativeArticle = regexp.MustCompile(`(?i:осы\s+бапт\p{L}*)$`)
+	kzRelativePoint   = regexp.MustCompile(`(?i:осы\s+тармақт\p{L}*)$`)
+	kzThisAct         = regexp.MustCompile(`(?i:осы\s+\p{L}+)$`)
+	kzExternalAct     = regexp.MustCompile(`(?i:((?:[\p{L}«»"-]+\s+){0,3}\p{L}*(?:кодексінің|заңының|конституциясының|жарлығының|қаулысының|бұйрығының)))$`)
+)
+
+type referenceMention struct {
+	level   string
+	numbers []string
+	start   int
+	end     int
+}
+
+// referenceScope says where a reference points: into the act itself, into
+// the article or point that contains it, or into another act.
+type referenceScope struct {
+	relative string
+	external string
+	start    int
+	end      int
+}
+
+// extractReferences finds the references in the names and text of every
+// node and resolves internal ones against the tree. Points and clauses carry
+// their wording in the name.
+func extractReferences(root *DocumentNode) []models.Reference {
+	index := make(map[string]*DocumentNode)
+	indexNodes(root, index)
+
+	var refs []models.Reference
+	var walk func(node *DocumentNode)
+	walk = func(node *DocumentNode) {
+		if node.Type != "ROOT" {
+			seen := make(map[string]bool)
+			for _, text := range []string{node.NameRu, node.NameKz, node.TextRu, node.TextKz} {
+				for _, ref := range nodeReferences(node, text, index) {
+					key := ref.TargetType + " " + ref.TargetArticleID + " " + ref.TargetPointID + " " + ref.TargetNumber + " " + ref.External
+					if !seen[key] {
+						seen[key] = true
+						refs = append(refs, ref)
+					}
+				}
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return refs
+}
+
+// indexNodes records the first node of every level numbered through the
+// whole act, which is enough to find the start of any reference chain.
+// Paragraphs are found under their chapter.
+func indexNodes(node *DocumentNode, index map[string]*DocumentNode) {
+	if continuousNumbering[node.Type] {
+		key := node.Type + " " + node.ID
+		if _, ok := index[key]; !ok {
+			index[key] = node
+		}
+	}
+	for _, child := range node.Children {
+		indexNodes(child, index)
+	}
+}
+
+func nodeReferences(node *DocumentNode, text string, index map[string]*DocumentNode) []models.Reference {
+	var refs []models.Reference
+	for _, chain := range referenceChains(text) {
+		scope := chainScope(text, chain)
+
+		start, end := chain[0].start, chain[len(chain)-1].end
+		if scope.start < start {
+			start = scope.start
+		}
+		if scope.end > end {
+			end = scope.end
+		}
+		fragment := strings.TrimSpace(text[start:end])
+
+		for _, path := range chainPaths(chain) {
+			ref := models.Reference{
+				NodeType:          node.Type,
+				NodeNumber:        node.ID,
+				ParentArticleID:   node.ParentIDs["ARTICLE"],
+				ParentParagraphID: node.ParentIDs["PARAGRAPH"],
+				ParentChapterID:   node.ParentIDs["CHAPTER"],
+				ParentSectionID:   node.ParentIDs["SECTION"],
+				ParentPartID:      node.ParentIDs["PART"],
+				External:          scope.external,
+				Text:              fragment,
+				Active:            node.inForce(),
+				Source:            node.source(),
+			}
+			resolveReference(&ref, node, path, scope, index)
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// referenceChains groups the level mentions of a text into chains of
+// mentions separated by nothing but spaces, one chain per reference.
+func referenceChains(text string) [][]referenceMention {
+	var mentions []referenceMention
+	for _, m := range ruReferenceRegex.FindAllStringSubmatchIndex(text, -1) {
+		mentions = append(mentions, referenceMention{
+			level:   referenceLevels[strings.ToLower(text[m[2]:m[3]])],
+			numbers: referenceNumberRegex.FindAllString(text[m[4]:m[5]], -1),
+			start:   m[2],
+			end:     m[5],
+		})
+	}
+	for _, m := range kzReferenceRegex.FindAllStringSubmatchIndex(text, -1) {
+		mentions = append(mentions, referenceMention{
+			level:   referenceLevels[strings.ToLower(text[m[4]:m[5]])],
+			numbers: referenceNumberRegex.FindAllString(text[m[2]:m[3]], -1),
+			start:   m[2],
+			end:     m[1],
+		})
+	}
+	sortMentions(mentions)
+
+	var chains [][]referenceMention
+	for _, mention := range mentions {
+		if n := len(chains); n > 0 {
+			chain := chains[n-1]
+			last := chain[len(chain)-1]
+			if mention.start >= last.end && strings.TrimSpace(text[last.end:mention.start]) == "" && !chainHasLevel(chain, mention.level) {
+				chains[n-1] = append(chain, mention)
+				continue
+			}
+		}
+		chains = append(chains, []referenceMention{mention})
+	}
+	return chains
+}
+
+func sortMentions(mentions []referenceMention) {
+	for i := 1; i < len(mentions); i++ {
+		for j := i; j > 0 && mentions[j].start < mentions[j-1].start; j-- {
+			mentions[j], mentions[j-1] = mentions[j-1], mentions[j]
+		}
+	}
+}
+
+func chainHasLevel(chain []referenceMention, level string) bool {
+	for _, mention := range chain {
+		if mention.level == level {
+			return true
+		}
+	}
+	return false
+}
+
+// chainPaths expands a chain into one path per cited provision: "статьями 10
+// и 12" cites two articles.
+func chainPaths(chain []referenceMention) []map[string]string {
+	paths := []map[string]string{{}}
+	for _, mention := range chain {
+		var next []map[string]string
+		for _, path := range paths {
+			for _, number := range mention.numbers {
+				expanded := make(map[string]string, len(path)+1)
+				for level, id := range path {
+					expanded[level] = id
+				}
+				expanded[mention.level] = number
+				next = append(next, expanded)
+			}
+		}
+		paths = next
+	}
+	return paths
+}
+
+// chainScope reads the words around a chain: "настоящей статьи" and "осы
+// баптың" make it relative to the current article, the name of another act
+// makes it external.
+func chainScope(text string, chain []referenceMention) referenceScope {
+	start, end := chain[0].start, chain[len(chain)-1].end
+	scope := referenceScope{start: start, end: end}
+
+	after := strings.TrimLeft(text[end:], " \t")
+	offset := len(text) - len(after)
+	if m := ruRelativeArticle.FindString(after); m != "" {
+		scope.relative, scope.end = "ARTICLE", offset+len(m)
+		return scope
+	}
+	if m := ruRelativePoint.FindString(after); m != "" {
+		scope.relative, scope.end = "POINT", offset+len(m)
+		return scope
+	}
+	if m := ruThisAct.FindString(after); m != "" {
+		scope.end = offset + len(m)
+		return scope
+	}
+	if m := ruExternalAct.FindStringSubmatch(after); m != nil {
+		scope.external, scope.end = strings.TrimSpace(m[1]), offset+len(m[1])
+		return scope
+	}
+
+	before := strings.TrimRight(text[:start], " \t")
+	if m := kzRelativeArticle.FindString(before); m != "" {
+		scope.relative, scope.start = "ARTICLE", len(before)-len(m)
+		return scope
+	}
+	if m := kzRelativePoint.FindString(before); m != "" {
+		scope.relative, scope.start = "POINT", len(before)-len(m)
+		return scope
+	}
+	if m := kzThisAct.FindString(before); m != "" {
+		scope.start = len(before) - len(m)
+		return scope
+	}
+	if m := kzExternalAct.FindStringSubmatch(before); m != nil {
+		name := strings.TrimSpace(m[1])
+		scope.external, scope.start = name, len(before)-len(name)
+		return scope
+	}
+
+	// Without a qualifier, a reference to a point or subclause alone is read
+	// within the current article.
+	if !chainHasLevel(chain, "ARTICLE") && !chainHasLevel(chain, "CHAPTER") &&
+		!chainHasLevel(chain, "PARAGRAPH") && !chainHasLevel(chain, "SECTION") {
+		scope.relative = "ARTICLE"
+		if !chainHasLevel(chain, "POINT") {
+			scope.relative = "POINT"
+		}
+	}
+	return scope
+}
+
+// resolveReference fills the target of a reference and, for internal ones,
+// looks the target up in the tree.
+func resolveReference(ref *models.Reference, node *DocumentNode, path map[string]string, scope referenceScope, index map[string]*DocumentNode) {
+	for i := len(nodeTypes) - 1; i >= 0; i-- {
+		if id, ok := path[nodeTypes[i]]; ok {
+			ref.TargetType, ref.TargetNumber = nodeTypes[i], id
+			break
+		}
+	}
+	if scope.external != "" {
+		delete(path, ref.TargetType)
+		targetIDs(ref, path)
+		return
+	}
+
+	// Relative references borrow the missing levels from the citing node.
+	if scope.relative != "" {
+		for _, nodeType := range []string{"ARTICLE", "POINT"} {
+			if _, ok := path[nodeType]; !ok {
+				if id := enclosingID(node, nodeType); id != "" {
+					path[nodeType] = id
+				}
+			}
+			if nodeType == scope.relative {
+				break
+			}
+		}
+	}
+
+	// Paragraphs are numbered afresh in every chapter, so one cited without
+	// its chapter is in the chapter of the citing node.
+	if _, ok := path["PARAGRAPH"]; ok {
+		if _, ok := path["CHAPTER"]; !ok {
+			if id := enclosingID(node, "CHAPTER"); id != "" {
+				path["CHAPTER"] = id
+			}
+		}
+	}
+
+	// The lookup starts from the finest level numbered through the whole act
+	// and walks down from there.
+	start := -1
+	for i, nodeType := range nodeTypes {
+		if _, ok := path[nodeType]; ok && continuousNumbering[nodeType] {
+			start = i
+		}
+	}
+
+	var target *DocumentNode
+	if start >= 0 {
+		target = index[nodeTypes[start]+" "+path[nodeTypes[start]]]
+		for _, nodeType := range nodeTypes[start+1:] {
+			id, ok := path[nodeType]
+			if !ok || target == nil {
+				continue
+			}
+			target = childByID(target, nodeType, id)
+		}
+	}
+
+	if target != nil && target.Type == ref.TargetType {
+		ref.Resolved = true
+		ref.TargetNumber = target.ID
+		targetIDs(ref, target.ParentIDs)
+		return
+	}
+	delete(path, ref.TargetType)
+	targetIDs(ref, path)
+}
+
+func targetIDs(ref *models.Reference, ids map[string]string) {
+	ref.TargetClauseID = ids["CLAUSE"]
+	ref.TargetPointID = ids["POINT"]
+	ref.TargetArticleID = ids["ARTICLE"]
+	ref.TargetParagraphID = ids["PARAGRAPH"]
+	ref.TargetChapterID = ids["CHAPTER"]
+	ref.TargetSectionID = ids["SECTION"]
+	ref.TargetPartID = ids["PART"]
+}
+
+func enclosingID(node *DocumentNode, nodeType string) string {
+	if node.Type == nodeType {
+		return node.ID
+	}
+	return node.ParentIDs[nodeType]
+}
+
+func childByID(node *DocumentNode, nodeType, id string) *DocumentNode {
+	for _, child := range node.Children {
+		if child.Type == nodeType && child.ID == id {
+			return child
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+func TestReferencesAreExtractedAndResolved(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(`Статья 1. Понятия
+1. Налог исчисляется в соответствии со статьей 15 настоящего Кодекса.
+2. В случаях, предусмотренных подпунктом 3) пункта 2 статьи 40, и статьями 10 и 12.
+3. Для целей пункта 1 настоящей статьи применяется статья 7 Гражданского кодекса Республики Казахстан.
+Статья 10. Десятая
+Текст.
+Статья 15. Пятнадцатая
+Текст.
+Статья 40. Сороковая
+1. Первый.
+2. Второй:
+1) один;
+3) три.
+15-бап. Он бесінші
+Осы Кодекстің 40-бабының 2-тармағының 3) тармақшасында және Қазақстан Республикасы Азаматтық кодексінің 7-бабында көзделген.`)
+	refs := p.ConvertToFlatData().References
+
+	want := []struct {
+		node, targetType, targetNumber, article, point string
+		resolved                                       bool
+		external                                       string
+	}{
+		{"1", "ARTICLE", "15", "", "", true, ""},
+		{"2", "CLAUSE", "3", "40", "2", true, ""},
+		{"2", "ARTICLE", "10", "", "", true, ""},
+		{"2", "ARTICLE", "12", "", "", false, ""},
+		{"3", "POINT", "1", "1", "", true, ""},
+		{"3", "ARTICLE", "7", "", "", false, "Гражданского кодекса Республики Казахстан"},
+		{"15", "CLAUSE", "3", "40", "2", true, ""},
+		{"15", "ARTICLE", "7", "", "", false, "Қазақстан Республикасы Азаматтық кодексінің"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %d references, want %d: %+v", len(refs), len(want), refs)
+	}
+	for i, w := range want {
+		r := refs[i]
+		if r.NodeNumber != w.node || r.TargetType != w.targetType || r.TargetNumber != w.targetNumber ||
+			r.TargetArticleID != w.article || r.TargetPointID != w.point || r.Resolved != w.resolved || r.External != w.external {
+			t.Errorf("reference %d: got %+v, want %+v", i, r, w)
+		}
+	}
+
+	if got := len(models.CodeData{References: refs}.CitedBy("40")); got != 2 {
+		t.Errorf("article 40 cited %d times, want 2", got)
+	}
+}
+
+func TestParagraphReferencesResolveWithinTheChapter(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(`Глава 1. Первая
+Параграф 1. Общие положения
+Статья 1. Первая
+Текст.
+Параграф 2. Особые положения
+Статья 2. Вторая
+Текст.
+Глава 2. Вторая
+Параграф 1. Общие положения
+Статья 3. Третья
+Применяется в соответствии с параграфом 2 главы 1.
+Параграф 2. Особые положения
+Статья 4. Четвертая
+Положения параграфа 1 применяются.`)
+	refs := p.ConvertToFlatData().References
+
+	want := []struct{ node, number, chapter string }{
+		{"3", "2", "1"},
+		{"4", "1", "2"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %d references, want %d: %+v", len(refs), len(want), refs)
+	}
+	for i, w := range want {
+		r := refs[i]
+		if r.NodeNumber != w.node || r.TargetType != "PARAGRAPH" || r.TargetNumber != w.number || r.TargetChapterID != w.chapter || !r.Resolved {
+			t.Errorf("reference %d: got %+v, want paragraph %s of chapter %s", i, r, w.number, w.chapter)
+		}
+	}
+}
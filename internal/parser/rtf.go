@@ -0,0 +1,356 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var rtfSkippedDestinations = map[string]bool{
+	"colortbl":          true,
+	"info":              true,
+	"pict":              true,
+	"object":            true,
+	"header":            true,
+	"headerl":           true,
+	"headerr":           true,
+	"headerf":           true,
+	"footer":            true,
+	"footerl":           true,
+	"footerr":           true,
+	"footerf":           true,
+	"footnote":          true,
+	"fldinst":           true,
+	"listtable":         true,
+	"listoverridetable": true,
+	"revtbl":            true,
+	"rsidtbl":           true,
+}
+
+var rtfSymbols = map[string]string{
+	"tab":       "\t",
+	"emdash":    "—",
+	"endash":    "–",
+	"bullet":    "•",
+	"lquote":    "‘",
+	"rquote":    "’",
+	"ldblquote": "“",
+	"rdblquote": "”",
+	"emspace":   " ",
+	"enspace":   " ",
+	"qmspace":   " ",
+}
+
+// rtfCharsets maps \fcharset values to the Windows code page they imply.
+var rtfCharsets = map[int]int{
+	161: 1253,
+	162: 1254,
+	177: 1255,
+	178: 1256,
+	186: 1257,
+	163: 1258,
+	204: 1251,
+	238: 1250,
+}
+
+type rtfGroup struct {
+	dest     string
+	skip     bool
+	uc       int
+	codepage int
+}
+
+type rtfReader struct {
+	data  []byte
+	pos   int
+	group rtfGroup
+	stack []rtfGroup
+
+	ansiCodepage int
+	fonts        map[int]int
+	styles       map[int]string
+	fontNum      int
+	styleNum     int
+	styleName    strings.Builder
+	pendingSkip  int
+
+	lines []Line
+	text  strings.Builder
+	style string
+}
+
+func readRTF(filePath string) (*Source, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rtf: %v", err)
+	}
+	return parseRTF(data)
+}
+
+func parseRTF(data []byte) (*Source, error) {
+	if !strings.HasPrefix(string(data), "{\\rtf") {
+		return nil, fmt.Errorf("error reading rtf: missing {\\rtf header")
+	}
+
+	r := &rtfReader{
+		data:         data,
+		group:        rtfGroup{uc: 1, codepage: 1252},
+		ansiCodepage: 1252,
+		fonts:        make(map[int]int),
+		styles:       make(map[int]string),
+	}
+	r.parse()
+
+	return &Source{Encoding: fmt.Sprintf("windows-%d", r.ansiCodepage), Lines: r.lines}, nil
+}
+
+func (r *rtfReader) parse() {
+	for r.pos < len(r.data) {
+		c := r.data[r.pos]
+		r.pos++
+
+		switch c {
+		case '{':
+			r.stack = append(r.stack, r.group)
+			if r.group.dest == "stylesheet" {
+				r.styleNum = 0
+				r.styleName.Reset()
+			}
+		case '}':
+			if len(r.stack) == 0 {
+				continue
+			}
+			r.group = r.stack[len(r.stack)-1]
+			r.stack = r.stack[:len(r.stack)-1]
+		case '\\':
+			r.parseControl()
+		case '\r', '\n':
+		default:
+			r.writeBytes([]byte{c})
+		}
+	}
+	r.flush()
+}
+
+func (r *rtfReader) parseControl() {
+	if r.pos >= len(r.data) {
+		return
+	}
+
+	c := r.data[r.pos]
+	if !isASCIILetter(c) {
+		r.pos++
+		r.controlSymbol(c)
+		return
+	}
+
+	start := r.pos
+	for r.pos < len(r.data) && isASCIILetter(r.data[r.pos]) {
+		r.pos++
+	}
+	word := string(r.data[start:r.pos])
+
+	hasParam := false
+	param := 0
+	numStart := r.pos
+	if r.pos < len(r.data) && r.data[r.pos] == '-' {
+		r.pos++
+	}
+	for r.pos < len(r.data) && r.data[r.pos] >= '0' && r.data[r.pos] <= '9' {
+		r.pos++
+	}
+	if r.pos > numStart && string(r.data[numStart:r.pos]) != "-" {
+		param, _ = strconv.Atoi(string(r.data[numStart:r.pos]))
+		hasParam = true
+	} else {
+		r.pos = numStart
+	}
+	if r.pos < len(r.data) && r.data[r.pos] == ' ' {
+		r.pos++
+	}
+
+	r.controlWord(word, param, hasParam)
+}
+
+func (r *rtfReader) controlSymbol(c byte) {
+	switch c {
+	case '\'':
+		if r.pos+2 > len(r.data) {
+			return
+		}
+		value, err := strconv.ParseUint(string(r.data[r.pos:r.pos+2]), 16, 8)
+		r.pos += 2
+		if err == nil {
+			r.writeBytes([]byte{byte(value)})
+		}
+	case '*':
+		r.group.skip = true
+	case '~':
+		r.writeString(" ")
+	case '_':
+		r.writeString("-")
+	case '-':
+	case '\r', '\n':
+		r.controlWord("par", 0, false)
+	default:
+		r.writeBytes([]byte{c})
+	}
+}
+
+func (r *rtfReader) controlWord(word string, param int, hasParam bool) {
+	switch word {
+	case "ansicpg":
+		r.ansiCodepage = param
+		r.group.codepage = param
+		return
+	case "fonttbl", "stylesheet":
+		r.group.dest = word
+		return
+	case "bin":
+		// The parameter comes from the file: a negative count would move
+		// back onto this control word and loop forever.
+		if param > 0 {
+			r.pos = min(r.pos+param, len(r.data))
+		}
+		return
+	case "uc":
+		r.group.uc = param
+		return
+	case "u":
+		if param < 0 {
+			param += 65536
+		}
+		r.writeString(string(rune(param)))
+		r.pendingSkip = r.group.uc
+		return
+	}
+
+	if rtfSkippedDestinations[word] {
+		r.group.skip = true
+		return
+	}
+	if r.group.skip {
+		return
+	}
+
+	switch r.group.dest {
+	case "fonttbl":
+		switch word {
+		case "f":
+			r.fontNum = param
+			r.fonts[param] = r.ansiCodepage
+		case "fcharset":
+			if codepage, ok := rtfCharsets[param]; ok {
+				r.fonts[r.fontNum] = codepage
+			}
+		case "cpg":
+			r.fonts[r.fontNum] = param
+		}
+		return
+	case "stylesheet":
+		if word == "s" {
+			r.styleNum = param
+		}
+		return
+	}
+
+	switch word {
+	case "par", "line", "sect", "page", "cell", "row":
+		r.flush()
+	case "pard":
+		r.style = ""
+	case "s":
+		r.style = r.styles[param]
+	case "plain":
+		r.group.codepage = r.ansiCodepage
+	case "f":
+		if codepage, ok := r.fonts[param]; ok && hasParam {
+			r.group.codepage = codepage
+		}
+	default:
+		if symbol, ok := rtfSymbols[word]; ok {
+			r.writeString(symbol)
+		}
+	}
+}
+
+func (r *rtfReader) writeBytes(b []byte) {
+	if r.pendingSkip > 0 {
+		r.pendingSkip--
+		return
+	}
+	r.writeString(decodeCodepage(b, r.group.codepage))
+}
+
+func (r *rtfReader) writeString(s string) {
+	if r.group.skip {
+		return
+	}
+
+	switch r.group.dest {
+	case "fonttbl":
+	case "stylesheet":
+		for _, ch := range s {
+			if ch == ';' {
+				r.styles[r.styleNum] = strings.TrimSpace(r.styleName.String())
+				r.styleName.Reset()
+				continue
+			}
+			r.styleName.WriteRune(ch)
+		}
+	default:
+		r.text.WriteString(s)
+	}
+}
+
+func (r *rtfReader) flush() {
+	r.lines = append(r.lines, Line{Text: strings.TrimRight(r.text.String(), " \t"), Style: r.style})
+	r.text.Reset()
+}
+
+func decodeCodepage(b []byte, codepage int) string {
+	var cm *charmap.Charmap
+	switch codepage {
+	case 866:
+		cm = charmap.CodePage866
+	case 1250:
+		cm = charmap.Windows1250
+	case 1251:
+		cm = charmap.Windows1251
+	case 1253:
+		cm = charmap.Windows1253
+	case 1254:
+		cm = charmap.Windows1254
+	case 1255:
+		cm = charmap.Windows1255
+	case 1256:
+		cm = charmap.Windows1256
+	case 1257:
+		cm = charmap.Windows1257
+	case 1258:
+		cm = charmap.Windows1258
+	case 10000:
+		cm = charmap.Macintosh
+	case 10007:
+		cm = charmap.MacintoshCyrillic
+	case 20866:
+		cm = charmap.KOI8R
+	case 21866:
+		cm = charmap.KOI8U
+	default:
+		cm = charmap.Windows1252
+	}
+
+	decoded, err := cm.NewDecoder().Bytes(b)
+	if err != nil {
+		return string(b)
+	}
+	return string(decoded)
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRTF(t *testing.T) {
+	tests := []struct {
+		name string
+		rtf  string
+		want []string
+	}{
+		{"unicode with fallback", `{\rtf1\ansi\uc1\u1057?\u1090?\u1072?\u1090?\u1100?\u1103? 1\par}`, []string{"Статья 1"}},
+		{"longer fallback", `{\rtf1\ansi\uc2\u1043??\u1083??\u1072??\u1074??\u1072?? 2\par}`, []string{"Глава 2"}},
+		{"negative unicode", `{\rtf1\ansi\u-4064?x\par}`, []string{"\uf020x"}},
+		{"hex in cp1251", `{\rtf1\ansi\ansicpg1251 \'d1\'f2\'e0\'f2\'fc\'ff 3\par}`, []string{"Статья 3"}},
+		{"font charset", `{\rtf1\ansi{\fonttbl{\f1\fcharset204 Times New Roman;}}\f1 \'c3\'eb\'e0\'e2\'e0 4\par}`, []string{"Глава 4"}},
+		{"skipped destinations", `{\rtf1\ansi{\*\generator Riched20;}{\*\ignored {\nested text}more}{\info{\title T}}Body\par}`, []string{"Body"}},
+		{"style names", `{\rtf1\ansi{\stylesheet{\s1 heading 1;}}\pard\s1 Title\par}`, []string{"Title"}},
+	}
+
+	for _, tt := range tests {
+		src, err := parseRTF([]byte(tt.rtf))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, line := range src.Lines {
+			if line.Text != "" {
+				got = append(got, line.Text)
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	src, _ := parseRTF([]byte(`{\rtf1{\stylesheet{\s1 heading 1;}}\pard\s1 Title\par}`))
+	if src.Lines[0].Style != "heading 1" {
+		t.Errorf("style = %q", src.Lines[0].Style)
+	}
+}
+
+func TestParseRTFMalformedBin(t *testing.T) {
+	for _, rtf := range []string{`{\rtf1 \bin-7 text}`, `{\rtf1 \bin-100000 text}`, `{\rtf1 \bin99999 text}`} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if _, err := parseRTF([]byte(rtf)); err != nil {
+				t.Errorf("%s: %v", rtf, err)
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: parse did not finish", rtf)
+		}
+	}
+}
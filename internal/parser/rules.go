@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const DefaultProfile = "code"
+
+//go:embed rules/*.json
+var builtinRules embed.FS
+
+// Rules describes the hierarchy of one kind of legal act. Levels are listed
+// from the top of the tree down; every level may have several patterns, each
+// capturing the number in the first group and the heading name in the
+// second. A pattern tagged with a language ("ru" or "kz") puts the name
+// into that language's field; untagged patterns split "Ru / Kz" names or
+// fall back to guessing from the letters used.
+type Rules struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Levels      []LevelRule `json:"levels"`
+}
+
+// LevelRule describes one level of the hierarchy. A level that is not
+// optional must enclose the levels below it: their headings outside a node
+// of that level are read as plain text.
+type LevelRule struct {
+	Type     string        `json:"type"`
+	Optional bool          `json:"optional"`
+	Patterns []PatternRule `json:"patterns"`
+}
+
+type PatternRule struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+	Lang  string `json:"lang,omitempty"`
+}
+
+type level struct {
+	nodeType string
+	optional bool
+	patterns []levelPattern
+}
+
+type levelPattern struct {
+	name string
+	lang string
+	re   *regexp.Regexp
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = mustLoadBuiltinRules()
+)
+
+func mustLoadBuiltinRules() map[string]*Rules {
+	loaded, err := loadRulesDir(builtinRules, "rules")
+	if err != nil {
+		panic(err)
+	}
+	return loaded
+}
+
+// LoadProfiles adds the *.json rule files found in dir to the available
+// profiles. A file whose name matches a built-in profile replaces it. A
+// missing directory is not an error.
+func LoadProfiles(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	loaded, err := loadRulesDir(os.DirFS(dir), ".")
+	if err != nil {
+		return err
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	for name, rules := range loaded {
+		profiles[name] = rules
+	}
+	return nil
+}
+
+func Profiles() []string {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Profile(name string) (*Rules, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	rules, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule profile: %s", name)
+	}
+	return rules, nil
+}
+
+func LoadRules(filePath string) (*Rules, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rules: %v", err)
+	}
+	return parseRules(data, strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)))
+}
+
+func loadRulesDir(fsys fs.FS, dir string) (map[string]*Rules, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rules directory: %v", err)
+	}
+
+	loaded := make(map[string]*Rules)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return nil, fmt.Errorf("error reading rules %s: %v", entry.Name(), err)
+		}
+		rules, err := parseRules(data, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		loaded[rules.Name] = rules
+	}
+	return loaded, nil
+}
+
+func parseRules(data []byte, defaultName string) (*Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("error parsing rules %s: %v", defaultName, err)
+	}
+	if rules.Name == "" {
+		rules.Name = defaultName
+	}
+	if _, err := compileLevels(&rules); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func compileLevels(rules *Rules) ([]level, error) {
+	if len(rules.Levels) == 0 {
+		return nil, fmt.Errorf("rules %s: no levels defined", rules.Name)
+	}
+
+	rank := make(map[string]int, len(nodeTypes))
+	for i, nodeType := range nodeTypes {
+		rank[nodeType] = i
+	}
+
+	// Levels are listed from the outermost down, in the order of nodeTypes;
+	// the parser opens and closes nodes by their position in the list.
+	seen := make(map[string]bool)
+	prev := ""
+	levels := make([]level, 0, len(rules.Levels))
+	for _, rule := range rules.Levels {
+		if _, ok := rank[rule.Type]; !ok {
+			return nil, fmt.Errorf("rules %s: unknown level type %s", rules.Name, rule.Type)
+		}
+		if seen[rule.Type] {
+			return nil, fmt.Errorf("rules %s: level %s declared twice", rules.Name, rule.Type)
+		}
+		seen[rule.Type] = true
+		if prev != "" && rank[rule.Type] < rank[prev] {
+			return nil, fmt.Errorf("rules %s: level %s listed after %s", rules.Name, rule.Type, prev)
+		}
+		prev = rule.Type
+
+		compiled := level{nodeType: rule.Type, optional: rule.Optional}
+		for _, pattern := range rule.Patterns {
+			re, err := regexp.Compile(pattern.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("rules %s: pattern %s: %v", rules.Name, pattern.Name, err)
+			}
+			if re.NumSubexp() < 2 {
+				return nil, fmt.Errorf("rules %s: pattern %s must capture a number and a name", rules.Name, pattern.Name)
+			}
+			if pattern.Lang != "" && pattern.Lang != "ru" && pattern.Lang != "kz" {
+				return nil, fmt.Errorf("rules %s: pattern %s has unknown language %s", rules.Name, pattern.Name, pattern.Lang)
+			}
+			compiled.patterns = append(compiled.patterns, levelPattern{name: pattern.Name, lang: pattern.Lang, re: re})
+		}
+		if len(compiled.patterns) == 0 {
+			return nil, fmt.Errorf("rules %s: level %s has no patterns", rules.Name, rule.Type)
+		}
+		levels = append(levels, compiled)
+	}
+
+	return levels, nil
+}
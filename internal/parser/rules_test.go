@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRulesChecksLevels(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels string
+		err    string
+	}{
+		{"in order", `{"type": "CHAPTER", "patterns": [{"name": "chapter", "regex": "^Глава\\s+(\\d+)\\.\\s+(.+)$"}]},
+			{"type": "ARTICLE", "patterns": [{"name": "article", "regex": "^Статья\\s+(\\d+)\\.\\s+(.+)$"}]}`, ""},
+		{"out of order", `{"type": "ARTICLE", "patterns": [{"name": "article", "regex": "^Статья\\s+(\\d+)\\.\\s+(.+)$"}]},
+			{"type": "CHAPTER", "patterns": [{"name": "chapter", "regex": "^Глава\\s+(\\d+)\\.\\s+(.+)$"}]}`, "level CHAPTER listed after ARTICLE"},
+		{"twice", `{"type": "ARTICLE", "patterns": [{"name": "article", "regex": "^Статья\\s+(\\d+)\\.\\s+(.+)$"}]},
+			{"type": "ARTICLE", "patterns": [{"name": "article-kz", "regex": "^(\\d+)-бап\\.\\s+(.+)$"}]}`, "level ARTICLE declared twice"},
+		{"unknown type", `{"type": "BOOK", "patterns": [{"name": "book", "regex": "^Книга\\s+(\\d+)\\.\\s+(.+)$"}]}`, "unknown level type BOOK"},
+		{"one capture", `{"type": "ARTICLE", "patterns": [{"name": "article", "regex": "^Статья\\s+(\\d+)"}]}`, "must capture a number and a name"},
+	}
+	for _, tt := range tests {
+		_, err := parseRules([]byte(`{"name": "test", "levels": [`+tt.levels+`]}`), "test")
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
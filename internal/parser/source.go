@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// Line is a single line of text extracted from a source document together
+// with the layout information the reader was able to recover for it.
+// Number is 1-based. Offset is the byte offset of the line in a plain-text
+// file; for formats with markup it counts bytes of the extracted text.
+// TOC is set by readers that can tell a line was generated as a table of
+// contents, such as the entries of a Word TOC field. Original holds the text
+// as read when normalization has rewritten Text, and is empty otherwise.
+type Line struct {
+	Text     string
+	Style    string
+	Page     int
+	Anchor   string
+	Number   int
+	Offset   int
+	TOC      bool
+	Original string
+}
+
+type Source struct {
+	Format   string
+	Encoding string
+	Lines    []Line
+}
+
+var readers = map[string]func(filePath string) (*Source, error){
+	".txt":  readTXT,
+	".docx": readDOCX,
+	".html": readHTML,
+	".htm":  readHTML,
+	".pdf":  readPDF,
+	".doc":  readDOC,
+	".rtf":  readRTF,
+}
+
+func ReadFile(filePath string) (*Source, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	read, ok := readers[ext]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file format: %s", ext)
+	}
+
+	src, err := read(filePath)
+	if err != nil {
+		return nil, err
+	}
+	src.Format = strings.TrimPrefix(ext, ".")
+	numberLines(src.Lines)
+
+	return src, nil
+}
+
+func ParseDocument(filePath string) (*models.CodeData, error) {
+	src, err := ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSource(src, DefaultProfile)
+}
+
+// ParseSource parses src with the given rule profile and no other options.
+func ParseSource(src *Source, profile string) (*models.CodeData, error) {
+	doc, err := Parse(context.Background(), src, Options{Profile: profile})
+	if err != nil {
+		return nil, err
+	}
+	return doc.Data, nil
+}
+
+func readTXT(filePath string) (*Source, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading txt: %v", err)
+	}
+
+	encoding, text := decodeText(content)
+	base, size := encodedLayout(content, encoding)
+	return &Source{Encoding: encoding, Lines: splitEncodedLines(text, base, size)}, nil
+}
+
+// splitEncodedLines splits decoded text into numbered lines. Offsets start
+// at base and advance by size of each line, which maps them back to bytes
+// of the original encoding; nil size means the text is the file itself.
+func splitEncodedLines(content string, base int, size func(string) int) []Line {
+	rawLines := strings.Split(content, "\n")
+
+	lines := make([]Line, 0, len(rawLines))
+	offset := base
+	for i, raw := range rawLines {
+		lines = append(lines, Line{Text: strings.TrimSuffix(raw, "\r"), Number: i + 1, Offset: offset})
+		if size == nil {
+			offset += len(raw) + 1
+		} else {
+			offset += size(raw + "\n")
+		}
+	}
+	return lines
+}
+
+// numberLines fills in positions for readers that extract text from markup
+// and do not track them themselves.
+func numberLines(lines []Line) {
+	offset := 0
+	for i := range lines {
+		if lines[i].Number == 0 {
+			lines[i].Number = i + 1
+			lines[i].Offset = offset
+		}
+		offset += len(lines[i].Text) + 1
+	}
+}
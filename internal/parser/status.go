@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// statusTail is what may follow a status word for the text to be an
+// editorial status marker rather than ordinary wording: nothing but
+// punctuation, or the act, article or date that made the change.
+const statusTail = `(?:[\s.,;:)\]]*$|[\s,:)\]–—-]+(?:законом|заңымен|кодексом|указом|постановлением|решением|приказом|в\s+соответствии|в\s+редакции|с\s+\d|до\s+\d|ҚР|\d))`
+
+var (
+	repealedRegex  = regexp.MustCompile(`^[\[(]?(?i:(?:исключен[аоы]?|утратил[аио]?\s+силу|алып\s+тасталды|күшін\s+жойды)` + statusTail + `)`)
+	suspendedRegex = regexp.MustCompile(`^[\[(]?(?i:(?:приостановлен[аоы]?|тоқтатыла\s+тұр\p{L}*)` + statusTail + `)`)
+	statusDate     = regexp.MustCompile(`(\d{1,2})\.(\d{1,2})\.(\d{4})`)
+)
+
+var statusFilter = newRuneFilter(repealedRegex, suspendedRegex)
+
+// detectStatus recognises provisions that are no longer in force from the
+// text that replaces them, e.g. "Исключена Законом РК от 10.01.2018 № 133-VI"
+// or "Приостановлена до 01.01.2020 года". The effective date is the date
+// the provision stops (or resumes) applying when the text gives one, and
+// otherwise the date of the act that made the change.
+func detectStatus(text string) (models.Status, string) {
+	text = strings.TrimSpace(text)
+	if !statusFilter.mayMatch(text) {
+		return models.StatusActive, ""
+	}
+	switch {
+	case repealedRegex.MatchString(text):
+		return models.StatusRepealed, statusEffectiveDate(text, " с ")
+	case suspendedRegex.MatchString(text):
+		return models.StatusSuspended, statusEffectiveDate(text, " до ")
+	}
+	return models.StatusActive, ""
+}
+
+func statusEffectiveDate(text, preposition string) string {
+	lower := strings.ToLower(text)
+	if i := strings.Index(lower, preposition); i >= 0 {
+		if date := statusDate.FindStringSubmatch(text[i:]); date != nil {
+			return isoDate(date)
+		}
+	}
+	if date := statusDate.FindStringSubmatch(text); date != nil {
+		return isoDate(date)
+	}
+	return ""
+}
+
+// isoDate formats the day, month and year matched by statusDate as an ISO
+// date.
+func isoDate(date []string) string {
+	return calendarDate(atoi(date[3]), atoi(date[2]), atoi(date[1]))
+}
+
+// calendarDate returns the ISO form of a date, or "" for one that does not
+// exist, such as a misprinted 31.02.2020.
+func calendarDate(year, month, day int) string {
+	date := fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return ""
+	}
+	return date
+}
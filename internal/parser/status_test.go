@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+func TestDetectStatus(t *testing.T) {
+	tests := []struct {
+		text   string
+		status models.Status
+		date   string
+	}{
+		{"Исключена Законом РК от 10.01.2018 № 133-VI", models.StatusRepealed, "2018-01-10"},
+		{"Утратила силу с 1.07.2019 Законом РК от 05.04.2019 № 241-VI", models.StatusRepealed, "2019-07-01"},
+		{"Приостановлена до 01.01.2020 года", models.StatusSuspended, "2020-01-01"},
+		{"Алып тасталды - ҚР 10.01.2018 № 133-VI Заңымен.", models.StatusRepealed, "2018-01-10"},
+		{"Исключена Законом РК от 31.02.2020 № 300-VI", models.StatusRepealed, ""},
+		{"[Исключен]", models.StatusRepealed, ""},
+		{"исключен;", models.StatusRepealed, ""},
+		{"Исключительные случаи", models.StatusActive, ""},
+		{"приостановление действия лицензии;", models.StatusActive, ""},
+		{"исключение из реестра;", models.StatusActive, ""},
+		{"Исключение составляют случаи, предусмотренные статьей 5.", models.StatusActive, ""},
+		{"Исключены случаи, когда налог уплачен.", models.StatusActive, ""},
+	}
+	for _, tt := range tests {
+		status, date := detectStatus(tt.text)
+		if status != tt.status || date != tt.date {
+			t.Errorf("%q: got %s %q, want %s %q", tt.text, status, date, tt.status, tt.date)
+		}
+	}
+}
+
+func TestStatusWordsInOrdinaryWording(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(`Статья 1. Лицензии
+Исключение составляют случаи, предусмотренные статьей 5.
+Статья 2. Меры
+1) выдача лицензии;
+2) приостановление действия лицензии;
+3) исключение из реестра;
+4) исключен Законом РК от 10.01.2018 № 133-VI;`)
+	data := p.ConvertToFlatData()
+
+	if len(data.Articles) != 2 || len(data.Clauses) != 4 {
+		t.Fatalf("got %d articles and %d clauses, want 2 and 4", len(data.Articles), len(data.Clauses))
+	}
+	for _, article := range data.Articles {
+		if article.Status != models.StatusActive {
+			t.Errorf("article %s: status %s", article.ID, article.Status)
+		}
+	}
+	for i, clause := range data.Clauses {
+		want := models.StatusActive
+		if i == 3 {
+			want = models.StatusRepealed
+		}
+		if clause.Status != want {
+			t.Errorf("clause %s %q: status %s, want %s", clause.ID, clause.NameRu, clause.Status, want)
+		}
+	}
+}
+
+func TestInactiveProvisionsPropagate(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(`Статья 1. Действующая
+Сноска. Статья 1 с изменениями, внесенными Законом РК от 10.01.2018 № 133-VI.
+Текст.
+Статья 2. Приостановлена до 01.01.2020 года
+Сноска. Статья 2 приостановлена Законом РК от 05.04.2019 № 241-VI.
+1) подпункт в соответствии со статьей 1 настоящего Кодекса;
+Статья 3. Исключена Законом РК от 10.01.2018 № 133-VI`)
+	data := p.ConvertToFlatData()
+
+	if len(data.Articles) != 3 || len(data.Clauses) != 1 {
+		t.Fatalf("got %d articles and %d clauses, want 3 and 1", len(data.Articles), len(data.Clauses))
+	}
+	if !data.Articles[0].Active || data.Articles[1].Active || data.Articles[2].Active {
+		t.Errorf("article activity %v %v %v, want true false false",
+			data.Articles[0].Active, data.Articles[1].Active, data.Articles[2].Active)
+	}
+	if clause := data.Clauses[0]; clause.Active || clause.Status != models.StatusActive {
+		t.Errorf("clause of suspended article: status %s active %v", clause.Status, clause.Active)
+	}
+
+	if len(data.Notes) != 2 || len(data.References) != 1 {
+		t.Fatalf("got %d notes and %d references, want 2 and 1", len(data.Notes), len(data.References))
+	}
+
+	active := data.ActiveOnly()
+	if len(active.Articles) != 1 || len(active.Clauses) != 0 {
+		t.Errorf("active only: %d articles and %d clauses, want 1 and 0", len(active.Articles), len(active.Clauses))
+	}
+	if len(active.Notes) != 1 || active.Notes[0].NodeNumber != "1" || len(active.References) != 0 {
+		t.Errorf("active only: notes %+v, references %+v", active.Notes, active.References)
+	}
+	if warnings := Validate(NewParser().ParseDocument("Статья 1. Исключена")); len(warnings) != 0 {
+		t.Errorf("repealed article reported: %v", warnings)
+	}
+}
+
+func TestActiveOnlyTellsClausesOfDifferentPointsApart(t *testing.T) {
+	p := NewParser()
+	p.ParseDocument(`Статья 1. Основные понятия, используемые в настоящем Кодексе
+Статья 2. Меры
+1. Меры первого вида:
+1) исключен Законом РК от 10.01.2018 № 133-VI;
+Сноска. Подпункт 1) исключен Законом РК от 10.01.2018 № 133-VI.
+2. Меры второго вида:
+1) меры в соответствии со статьей 1 настоящего Кодекса;
+Сноска. Подпункт 1) с изменениями, внесенными Законом РК от 05.04.2019 № 241-VI.`)
+	data := p.ConvertToFlatData()
+
+	if len(data.Clauses) != 2 || data.Clauses[0].Active || !data.Clauses[1].Active {
+		t.Fatalf("clauses %+v", data.Clauses)
+	}
+	if len(data.Notes) != 2 || len(data.References) != 1 {
+		t.Fatalf("got %d notes and %d references, want 2 and 1", len(data.Notes), len(data.References))
+	}
+
+	active := data.ActiveOnly()
+	if len(active.Clauses) != 1 || active.Clauses[0].ParentPointID != "2" {
+		t.Errorf("active only: clauses %+v", active.Clauses)
+	}
+	if len(active.Notes) != 1 || active.Notes[0].ActDate != "2019-04-05" {
+		t.Errorf("active only: notes %+v", active.Notes)
+	}
+	if len(active.References) != 1 || active.References[0].TargetNumber != "1" {
+		t.Errorf("active only: references %+v", active.References)
+	}
+}
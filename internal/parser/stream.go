@@ -0,0 +1,330 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// maxHeadingLines bounds how many source lines one heading may span, so a
+// run of lines that all look like a heading's wording does not grow it
+// without end. Real headings wrap over four or five lines at most.
+const maxHeadingLines = 8
+
+// ParseReader parses plain UTF-8 text from r line by line and hands every
+// node to emit as soon as its heading, text and notes are complete, in
+// document order. Emitted nodes are not linked into a tree: Parent and
+// ParentIDs still name their ancestors, but only the nodes that are still
+// open are kept, so memory does not grow with the length of the document.
+// A table of contents is set aside rather than parsed; TOC returns its
+// entries. Parsing stops at the first error returned by emit.
+func (p *Parser) ParseReader(r io.Reader, emit func(*DocumentNode) error) error {
+	return p.parseReader(r, emit, false)
+}
+
+// FlattenNode adds a node emitted by ParseReader, with its notes, to data.
+// A node counts as active when neither it nor any of its ancestors has been
+// repealed or suspended. References and the glossary need the whole tree
+// and are left out.
+func FlattenNode(data *models.ParsedData, node *DocumentNode) {
+	appendFlat(node, node.inForce(), data)
+}
+
+// TOC returns the table of contents entries set aside by the last parse.
+func (p *Parser) TOC() []models.TOCEntry {
+	return p.toc
+}
+
+func (p *Parser) parseReader(r io.Reader, emit func(*DocumentNode) error, keep bool) error {
+	s := p.newParseState(emit, keep)
+	lines := newLineReader(r)
+	for {
+		line, ok, err := lines.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if err := s.feed(p.normalizeLine(line)); err != nil {
+			return err
+		}
+	}
+	return s.finish()
+}
+
+// lineReader splits a stream into numbered lines with byte offsets, the same
+// way splitEncodedLines does for a string.
+type lineReader struct {
+	r      *bufio.Reader
+	number int
+	offset int
+	done   bool
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReader(r)}
+}
+
+func (lr *lineReader) next() (Line, bool, error) {
+	if lr.done {
+		return Line{}, false, nil
+	}
+
+	raw, err := lr.r.ReadString('\n')
+	if err == io.EOF {
+		lr.done = true
+	} else if err != nil {
+		return Line{}, false, fmt.Errorf("error reading document: %v", err)
+	}
+
+	lr.number++
+	line := Line{
+		Text:   strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r"),
+		Number: lr.number,
+		Offset: lr.offset,
+	}
+	lr.offset += len(raw)
+	return line, true, nil
+}
+
+// parseState is the parser's state between two lines. A heading is held
+// back until the following lines show whether it wraps; a node is emitted
+// once the next heading is placed, since only the newest node can still
+// take text and notes.
+type parseState struct {
+	p       *Parser
+	open    []*DocumentNode
+	current *DocumentNode
+	emit    func(*DocumentNode) error
+	keep    bool
+
+	ctx      context.Context
+	lines    int
+	nodes    int
+	maxNodes int
+
+	heading      *Line
+	headingMatch heading
+	headingName  string
+	headingLines int
+
+	tocBlock tocBlock
+}
+
+// newParseState starts a new tree under a fresh root node.
+func (p *Parser) newParseState(emit func(*DocumentNode) error, keep bool) *parseState {
+	p.rootNode = newRootNode()
+	p.preamble = nil
+	p.toc = nil
+	p.normalized = nil
+	return &parseState{
+		p:       p,
+		open:    make([]*DocumentNode, len(p.levels)),
+		current: p.rootNode,
+		emit:    emit,
+		keep:    keep,
+		ctx:     context.Background(),
+	}
+}
+
+// feed moves the state on by one line. The line passes the TOC scan first,
+// which classifies it and holds back a block that may be a table of
+// contents.
+func (s *parseState) feed(line Line) error {
+	s.lines++
+	if s.lines%contextCheckLines == 0 {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return s.scanTOC(s.p.tocLine(line))
+}
+
+// readLine adds a line the TOC scan has passed on, reusing its
+// classification unless a page number was cut off for the match.
+func (s *parseState) readLine(info tocLine) error {
+	if info.hasPage {
+		info.heading, info.isHeading = s.p.classify(info.line.Text)
+	}
+	return s.addLine(info.line, info.heading, info.isHeading)
+}
+
+// addLine extends a wrapped heading, places the heading held back, adds a
+// note or body text to the current node, or holds back a new heading.
+func (s *parseState) addLine(line Line, h heading, isHeading bool) error {
+	if s.heading != nil {
+		if s.headingLines < maxHeadingLines && s.p.continuesHeading(s.headingName, line.Text, isHeading) {
+			s.headingName += " " + line.Text
+			s.heading.Text += " " + line.Text
+			s.headingLines++
+			return nil
+		}
+		if err := s.placeHeading(); err != nil {
+			return err
+		}
+	}
+
+	if line.Text == "" {
+		return nil
+	}
+
+	// A heading below a required level is only a heading inside a node of
+	// that level; elsewhere, e.g. a numbered list in the preamble, it is
+	// plain text.
+	if isHeading && !s.placeable(h.level) {
+		isHeading = false
+	}
+
+	// Editorial notes follow the heading they describe and are kept apart
+	// from the body text.
+	if isNoteLine(line.Text) {
+		s.current.Notes = append(s.current.Notes, line)
+		return nil
+	}
+
+	if !isHeading {
+		// Lines that start no structural node are the body of the node
+		// opened last, up to the next heading. Those before the first
+		// heading are the preamble.
+		if s.current == s.p.rootNode && len(s.p.preamble) < maxPreambleLines {
+			s.p.preamble = append(s.p.preamble, line)
+		}
+		// The body keeps the text as read; normalization only serves the
+		// matching.
+		text := line.Text
+		if line.Original != "" {
+			text = strings.TrimSpace(line.Original)
+		}
+		if s.current != s.p.rootNode && (s.p.language == "" || textLanguage(line.Text) == s.p.language) {
+			s.current.appendText(text)
+		}
+		return nil
+	}
+
+	s.heading = &line
+	s.headingMatch = h
+	s.headingName = h.match[2]
+	s.headingLines = 1
+	return nil
+}
+
+func (s *parseState) placeHeading() error {
+	line := *s.heading
+	s.heading = nil
+
+	h := s.headingMatch
+	if s.headingLines > 1 {
+		// Read the joined heading again so the pattern sees the whole name.
+		if match := h.pattern.re.FindStringSubmatch(line.Text); match != nil {
+			h.match = match
+		} else {
+			h.match = append([]string(nil), h.match...)
+			h.match[2] = s.headingName
+		}
+	}
+
+	s.nodes++
+	if s.maxNodes > 0 && s.nodes > s.maxNodes {
+		return fmt.Errorf("document has more than %d nodes", s.maxNodes)
+	}
+
+	node := s.openNode(line, h)
+	if s.keep {
+		node.Parent.Children = append(node.Parent.Children, node)
+	}
+
+	if err := s.emitCurrent(); err != nil {
+		return err
+	}
+	s.current = node
+	return nil
+}
+
+// openNode builds the node a heading opens and makes it the open node of
+// its level, closing the open nodes of the levels below. The node hangs
+// under its nearest open ancestor only: levels in between that the document
+// skips stay out of ParentIDs, and a node with no open ancestor at all
+// becomes a top-level node.
+func (s *parseState) openNode(line Line, h heading) *DocumentNode {
+	nodeID := strings.TrimSpace(h.match[1])
+	nodeName := h.match[2]
+	nameRu, nameKz := splitNamesForLang(nodeName, h.pattern.lang)
+	switch s.p.language {
+	case "ru":
+		nameKz = ""
+	case "kz":
+		nameRu = ""
+	}
+
+	node := &DocumentNode{
+		Type:      s.p.levels[h.level].nodeType,
+		ID:        nodeID,
+		SortKey:   sortKey(nodeID),
+		NameRu:    nameRu,
+		NameKz:    nameKz,
+		Style:     line.Style,
+		Page:      line.Page,
+		Anchor:    line.Anchor,
+		Line:      line.Number,
+		Offset:    line.Offset,
+		Heading:   line.Text,
+		Pattern:   h.pattern.name,
+		ParentIDs: make(map[string]string),
+		Children:  make([]*DocumentNode, 0),
+	}
+	node.Status, node.EffectiveDate = detectStatus(nodeName)
+
+	node.Parent = s.p.rootNode
+	for i := h.level - 1; i >= 0; i-- {
+		if ancestor := s.open[i]; ancestor != nil {
+			if node.Parent == s.p.rootNode {
+				node.Parent = ancestor
+			}
+			node.ParentIDs[ancestor.Type] = ancestor.ID
+		}
+	}
+
+	s.open[h.level] = node
+	for i := h.level + 1; i < len(s.open); i++ {
+		s.open[i] = nil
+	}
+	return node
+}
+
+// placeable reports whether a heading of the given level may open a node:
+// every level above it that the rules do not mark optional must have an
+// open node.
+func (s *parseState) placeable(level int) bool {
+	for i := 0; i < level; i++ {
+		if !s.p.levels[i].optional && s.open[i] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// finish passes on the lines still held back, places a heading still held
+// back and emits the last node.
+func (s *parseState) finish() error {
+	if err := s.flushTOC(); err != nil {
+		return err
+	}
+	if s.heading != nil {
+		if err := s.placeHeading(); err != nil {
+			return err
+		}
+	}
+	return s.emitCurrent()
+}
+
+func (s *parseState) emitCurrent() error {
+	if s.emit == nil || s.current == s.p.rootNode {
+		return nil
+	}
+	return s.emit(s.current)
+}
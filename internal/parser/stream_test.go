@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+func TestParseReaderEmitsNodesInOrder(t *testing.T) {
+	var data models.ParsedData
+	var order []string
+	err := NewParser().ParseReader(strings.NewReader(testCode), func(node *DocumentNode) error {
+		if len(node.Children) != 0 {
+			t.Errorf("%s %s emitted with children", node.Type, node.ID)
+		}
+		order = append(order, node.Type+" "+node.ID)
+		FlattenNode(&data, node)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewParser()
+	p.ParseDocument(testCode)
+	want := p.ConvertToFlatData()
+	if len(data.Articles) != len(want.Articles) || len(data.Clauses) != len(want.Clauses) || len(data.SubClauses) != len(want.SubClauses) {
+		t.Errorf("streamed %d articles, %d clauses, %d subclauses; want %d, %d, %d",
+			len(data.Articles), len(data.Clauses), len(data.SubClauses), len(want.Articles), len(want.Clauses), len(want.SubClauses))
+	}
+	for i := range want.Articles {
+		if i < len(data.Articles) && data.Articles[i] != want.Articles[i] {
+			t.Errorf("article %d: streamed %+v, want %+v", i, data.Articles[i], want.Articles[i])
+		}
+	}
+	if got := strings.Join(order[:5], ", "); got != "PART 1, SECTION 1, CHAPTER 1, PARAGRAPH 1, ARTICLE 1" {
+		t.Errorf("emitted %s", got)
+	}
+}
+
+func TestParseReaderStopsOnEmitError(t *testing.T) {
+	stop := errors.New("stop")
+	count := 0
+	err := NewParser().ParseReader(strings.NewReader(testCode), func(node *DocumentNode) error {
+		count++
+		if node.Type == "ARTICLE" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("got error %v, want %v", err, stop)
+	}
+	if count != 5 {
+		t.Errorf("emitted %d nodes before stopping, want 5", count)
+	}
+}
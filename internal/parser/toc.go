@@ -0,0 +1,376 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+const (
+	// minTOCRun is the fewest headings in a row, with no body text between
+	// them, that make a table of contents when the body lists them again.
+	minTOCRun = 5
+	// maxTOCLines bounds the block held back while it may still be a table
+	// of contents.
+	maxTOCLines = 5000
+	// maxTOCGap bounds the lines, a preamble most often, read after a dense
+	// run of headings while waiting for the first heading of the body.
+	maxTOCGap = 200
+)
+
+var (
+	// tocStyleRegex matches the paragraph styles Word gives TOC entries.
+	tocStyleRegex = regexp.MustCompile(`^(?i:toc\s*\d*|toc\s+heading)$`)
+	// tocPageRegex matches a page number after a dot leader, a tab or a
+	// wide gap at the end of an entry.
+	tocPageRegex  = regexp.MustCompile(`(?:\s*(?:\.{2,}|…+|·{2,}|_{2,})\s*|\t+\s*|\s{2,})(\d{1,4})$`)
+	tocTitleRegex = regexp.MustCompile(`^(?i:содержание|оглавление|мазмұны)$`)
+)
+
+// tocTypes are the levels a table of contents lists. Points and clauses are
+// left out: the same enumerations recur from article to article and would
+// pass for repeated entries.
+var tocTypes = map[string]bool{"PART": true, "SECTION": true, "CHAPTER": true, "PARAGRAPH": true, "ARTICLE": true}
+
+// tocLine is a line on its way to the parse state with what the TOC scan
+// learns about it. The line is classified once, here; the parse state reuses
+// the heading unless a page number was cut off for the match.
+type tocLine struct {
+	line      Line
+	text      string
+	heading   heading
+	isHeading bool
+	listed    bool
+	page      int
+	hasPage   bool
+	marked    bool
+}
+
+func (p *Parser) tocLine(line Line) tocLine {
+	line.Text = strings.TrimSpace(line.Text)
+	info := tocLine{line: line, text: line.Text}
+	info.marked = line.TOC || line.Style != "" && tocStyleRegex.MatchString(strings.TrimSpace(line.Style))
+	if endsInDigit(info.text) {
+		if m := tocPageRegex.FindStringSubmatchIndex(info.text); m != nil {
+			info.page, _ = strconv.Atoi(info.text[m[2]:m[3]])
+			info.hasPage = true
+			info.text = strings.TrimSpace(info.text[:m[0]])
+		}
+	}
+	info.heading, info.isHeading = p.classify(info.text)
+	info.listed = info.isHeading && tocTypes[p.levels[info.heading.level].nodeType]
+	return info
+}
+
+// endsInDigit spares the page regexp the lines that cannot end in a page
+// number, which are most body lines.
+func endsInDigit(text string) bool {
+	return text != "" && text[len(text)-1] >= '0' && text[len(text)-1] <= '9'
+}
+
+// tocBlock is the run of headings held back while it may still be a table
+// of contents.
+type tocBlock struct {
+	lines   []tocLine
+	numbers map[tocNumber]int
+	name    string
+	// pending is set once body text has ended a dense run with no other
+	// evidence; gap holds the lines read since, up to the next heading of
+	// a level the run lists.
+	pending bool
+	gap     []tocLine
+}
+
+// tocNumber indexes the listed headings of a block by level and number.
+type tocNumber struct {
+	level  int
+	number string
+}
+
+func newTOCNumber(info tocLine) tocNumber {
+	return tocNumber{info.heading.level, strings.TrimSpace(info.heading.match[1])}
+}
+
+func (b *tocBlock) add(info tocLine) {
+	if info.listed {
+		if b.numbers == nil {
+			b.numbers = make(map[tocNumber]int)
+		}
+		if _, ok := b.numbers[newTOCNumber(info)]; !ok {
+			b.numbers[newTOCNumber(info)] = len(b.lines)
+		}
+	}
+	if info.isHeading {
+		b.name = info.heading.match[2]
+	}
+	b.lines = append(b.lines, info)
+}
+
+// find returns where the block lists the heading of info: a heading of the
+// same level and number whose name starts with the same words, which a TOC
+// entry shares with the heading it points to even when one of them wraps.
+func (b *tocBlock) find(info tocLine) (int, bool) {
+	if !info.listed {
+		return 0, false
+	}
+	i, ok := b.numbers[newTOCNumber(info)]
+	if !ok || tocWords(b.lines[i].heading) != tocWords(info.heading) {
+		return 0, false
+	}
+	return i, true
+}
+
+// reset empties the block and keeps its storage for the next run. The
+// lines taken out before a reset are only valid until the next add.
+func (b *tocBlock) reset() {
+	clear(b.numbers)
+	*b = tocBlock{lines: b.lines[:0], numbers: b.numbers}
+}
+
+// bodyStart returns where the body starts among the first n lines of the
+// block when the heading at n, of the given level, belongs to the body. The
+// body may open with higher levels than the first heading the block lists,
+// "РАЗДЕЛ 1" above "Глава 1" in a TOC of chapters; such headings, of levels
+// the block has not listed before, go with the body.
+func (b *tocBlock) bodyStart(n, level int) int {
+	start := n
+	for k := n - 1; k >= 0; k-- {
+		info := b.lines[k]
+		if !info.listed {
+			continue
+		}
+		if info.heading.level >= level || b.listsLevel(k, info.heading.level) {
+			break
+		}
+		start, level = k, info.heading.level
+	}
+	return start
+}
+
+// listsLevel reports whether one of the first n lines is a listed heading
+// of the level.
+func (b *tocBlock) listsLevel(n, level int) bool {
+	for _, info := range b.lines[:n] {
+		if info.listed && info.heading.level == level {
+			return true
+		}
+	}
+	return false
+}
+
+// scanTOC sets the tables of contents aside on the way to the parse state
+// and keeps their entries. A block is read as a table of contents when the
+// reader or a TOC style marks its lines, when its headings end in page
+// numbers, or when it is a dense run of headings with no body text that the
+// body goes on to list again. Only the block in question is held back, so a
+// stream is read in bounded memory.
+func (s *parseState) scanTOC(info tocLine) error {
+	b := &s.tocBlock
+	if b.pending {
+		if !info.listed || !b.listsLevel(len(b.lines), info.heading.level) {
+			b.gap = append(b.gap, info)
+			if len(b.gap) > maxTOCGap {
+				return s.releaseTOC(false, 0)
+			}
+			return nil
+		}
+		// The first heading of a level the run lists tells whether the run
+		// lists the body.
+		_, listed := b.find(info)
+		if err := s.releaseTOC(listed, info.heading.level); err != nil {
+			return err
+		}
+	}
+
+	if len(b.lines) == 0 {
+		if !info.isHeading && !info.marked && !tocTitleRegex.MatchString(info.text) {
+			return s.readLine(info)
+		}
+		b.add(info)
+		return nil
+	}
+
+	if info.text != "" && !info.isHeading && !info.marked && !info.hasPage && !s.p.continuesHeading(b.name, info.text, false) {
+		// Body text ends the run.
+		if err := s.endTOCRun(); err != nil {
+			return err
+		}
+		if b.pending {
+			b.gap = append(b.gap, info)
+			return nil
+		}
+		return s.readLine(info)
+	}
+
+	if first, ok := b.find(info); ok {
+		// The body starts listing the run again.
+		return s.splitTOCRun(info, first)
+	}
+
+	b.add(info)
+	if len(b.lines) >= maxTOCLines {
+		return s.endTOCRun()
+	}
+	return nil
+}
+
+// splitTOCRun ends the run at info, a heading the run has listed before at
+// first: the lines up to the start of the body are a table of contents, and
+// the rest is scanned again.
+func (s *parseState) splitTOCRun(info tocLine, first int) error {
+	b := &s.tocBlock
+	end := max(b.bodyStart(len(b.lines), info.heading.level), first+1)
+	// The rest is scanned into the block again, so it cannot share its
+	// storage.
+	lines := append([]tocLine(nil), b.lines...)
+	b.reset()
+
+	toc := lines[:end]
+	marked, paged, listed := tocEvidence(toc)
+	if marked > 0 || (paged >= 2 && paged*2 >= listed) || listed >= minTOCRun {
+		s.takeTOC(toc)
+	} else {
+		for _, line := range toc {
+			if err := s.readLine(line); err != nil {
+				return err
+			}
+		}
+	}
+	for _, line := range lines[end:] {
+		if err := s.scanTOC(line); err != nil {
+			return err
+		}
+	}
+	return s.scanTOC(info)
+}
+
+// endTOCRun decides on a run ended by body text or by its length. A marked
+// or paged run ends at its last line with such evidence, so body headings
+// that follow it directly stay in the body. A dense run waits for the next
+// listed heading.
+func (s *parseState) endTOCRun() error {
+	b := &s.tocBlock
+	end := 0
+	for i, info := range b.lines {
+		if info.marked || info.hasPage {
+			end = i + 1
+		}
+	}
+	marked, paged, listed := tocEvidence(b.lines[:end])
+	if marked > 0 || (paged >= 2 && paged*2 >= listed) {
+		lines := b.lines
+		b.reset()
+		s.takeTOC(lines[:end])
+		for _, line := range lines[end:] {
+			if err := s.readLine(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, _, listed := tocEvidence(b.lines); listed >= minTOCRun {
+		b.pending = true
+		return nil
+	}
+	return s.releaseTOC(false, 0)
+}
+
+// releaseTOC passes on the block held back, as a table of contents up to
+// the start of the body whose first heading is of the given level when
+// isTOC is set, and the lines read after it.
+func (s *parseState) releaseTOC(isTOC bool, level int) error {
+	b := &s.tocBlock
+	lines, gap := b.lines, b.gap
+	end := 0
+	if isTOC {
+		end = b.bodyStart(len(lines), level)
+	}
+	b.reset()
+
+	s.takeTOC(lines[:end])
+	for _, line := range lines[end:] {
+		if err := s.readLine(line); err != nil {
+			return err
+		}
+	}
+	for _, line := range gap {
+		if err := s.readLine(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushTOC releases the block still held back at the end of the document.
+func (s *parseState) flushTOC() error {
+	b := &s.tocBlock
+	if len(b.lines) > 0 && !b.pending {
+		if err := s.endTOCRun(); err != nil {
+			return err
+		}
+	}
+	if len(b.lines) > 0 {
+		return s.releaseTOC(false, 0)
+	}
+	return nil
+}
+
+func tocEvidence(lines []tocLine) (marked, paged, listed int) {
+	for _, info := range lines {
+		if info.marked {
+			marked++
+		}
+		if info.listed {
+			listed++
+			if info.hasPage {
+				paged++
+			}
+		}
+	}
+	return marked, paged, listed
+}
+
+func (s *parseState) takeTOC(lines []tocLine) {
+	for _, info := range lines {
+		s.p.toc = s.p.appendTOCEntry(s.p.toc, info)
+	}
+}
+
+func (p *Parser) appendTOCEntry(entries []models.TOCEntry, info tocLine) []models.TOCEntry {
+	switch {
+	case info.text == "" || tocTitleRegex.MatchString(info.text):
+		return entries
+	case !info.isHeading && !info.marked && len(entries) > 0:
+		// The wrapped tail of the previous entry.
+		last := &entries[len(entries)-1]
+		last.Title += " " + info.text
+		if info.hasPage {
+			last.Page = info.page
+		}
+		return entries
+	}
+
+	entry := models.TOCEntry{Title: info.text, Page: info.page, Line: info.line.Number}
+	if info.isHeading {
+		entry.NodeType = p.levels[info.heading.level].nodeType
+		entry.Number = strings.TrimSpace(info.heading.match[1])
+		entry.Title = strings.TrimSpace(info.heading.match[2])
+	}
+	return append(entries, entry)
+}
+
+// tocWords returns the first words of a heading's name, lower-cased and
+// without punctuation.
+func tocWords(h heading) string {
+	words := strings.Fields(strings.ToLower(h.match[2]))
+	if len(words) > 3 {
+		words = words[:3]
+	}
+	for i, word := range words {
+		words[i] = strings.Trim(word, ".,;:")
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableOfContentsIsSetAside(t *testing.T) {
+	tests := []struct {
+		name string
+		toc  string
+	}{
+		{"dot leaders", "Содержание\nГлава 1. ОСНОВНЫЕ ПОЛОЖЕНИЯ ........ 3\nСтатья 1. Предмет регулирования ........ 3\nГлава 2. ПРИНЦИПЫ ........ 5\nСтатья 2. Принцип законности ........ 5\n"},
+		{"dense headings", "ЧАСТЬ 1. ОБЩАЯ ЧАСТЬ\nРАЗДЕЛ 1. ОБЩИЕ ПОЛОЖЕНИЯ\nГлава 1. ОСНОВНЫЕ ПОЛОЖЕНИЯ\nСтатья 1. Предмет регулирования\nГлава 2. ПРИНЦИПЫ\nСтатья 2. Принцип законности\nСтатья 2-1. Принцип справедливости\n"},
+		{"dense headings before a preamble", "Глава 1. ОСНОВНЫЕ ПОЛОЖЕНИЯ\nСтатья 1. Предмет регулирования\nГлава 2. ПРИНЦИПЫ\nСтатья 2. Принцип законности\nСтатья 2-1. Принцип справедливости\nСтатья 3. Учет налогоплательщиков\nНастоящий Кодекс регулирует налоговые отношения.\n"},
+		{"chapters above an unlisted part", "Глава 1. ОСНОВНЫЕ ПОЛОЖЕНИЯ\nСтатья 1. Предмет регулирования\nГлава 2. ПРИНЦИПЫ\nСтатья 2. Принцип законности\nСтатья 2-1. Принцип справедливости\nСтатья 3. Учет налогоплательщиков\n"},
+	}
+
+	p := NewParser()
+	p.ParseDocument(testCode)
+	want := p.ConvertToFlatData()
+
+	parses := []struct {
+		name  string
+		parse func(p *Parser, text string)
+	}{
+		{"ParseLines", func(p *Parser, text string) { p.ParseLines(testSource(text).Lines) }},
+		{"ParseDocument", func(p *Parser, text string) { p.ParseDocument(text) }},
+	}
+	for _, tt := range tests {
+		for _, parse := range parses {
+			p := NewParser()
+			parse.parse(p, tt.toc+testCode)
+			got := p.ConvertToFlatData()
+			if len(got.TOC) < 4 {
+				t.Errorf("%s, %s: found %d TOC entries", tt.name, parse.name, len(got.TOC))
+			}
+			if len(got.Parts) != len(want.Parts) || len(got.Sections) != len(want.Sections) ||
+				len(got.Articles) != len(want.Articles) || len(got.Chapters) != len(want.Chapters) {
+				t.Errorf("%s, %s: got %d parts, %d sections, %d chapters, %d articles; want %d, %d, %d, %d",
+					tt.name, parse.name, len(got.Parts), len(got.Sections), len(got.Chapters), len(got.Articles),
+					len(want.Parts), len(want.Sections), len(want.Chapters), len(want.Articles))
+			}
+			if len(got.Points) != len(want.Points) || got.Points[0].ParentArticleID != "1" {
+				t.Errorf("%s, %s: got points %+v", tt.name, parse.name, got.Points)
+			}
+		}
+
+		p := NewParser()
+		articles := 0
+		err := p.ParseReader(strings.NewReader(tt.toc+testCode), func(node *DocumentNode) error {
+			if node.Type == "ARTICLE" {
+				articles++
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.TOC()) < 4 || articles != len(want.Articles) {
+			t.Errorf("%s, ParseReader: %d TOC entries, %d articles", tt.name, len(p.TOC()), articles)
+		}
+	}
+
+	p = NewParser()
+	p.ParseLines(testSource(testCode).Lines)
+	if toc := p.ConvertToFlatData().TOC; len(toc) != 0 {
+		t.Errorf("found TOC entries in a document without one: %+v", toc)
+	}
+}
+
+func TestDenseHeadingsWithoutRepeatsAreBody(t *testing.T) {
+	text := "Глава 1. ОБЩИЕ\nСтатья 1. Исключена\nСтатья 2. Исключена\nСтатья 3. Исключена\nСтатья 4. Исключена\nСтатья 5. Исключена\nСтатья 6. Действует\nТекст статьи.\nСтатья 7. Последняя\nТекст."
+	p := NewParser()
+	p.ParseDocument(text)
+	data := p.ConvertToFlatData()
+	if len(data.TOC) != 0 || len(data.Articles) != 7 {
+		t.Errorf("got %d TOC entries and %d articles, want 0 and 7", len(data.TOC), len(data.Articles))
+	}
+}
+
+func TestDOCXMarksTOCField(t *testing.T) {
+	body := `<w:document xmlns:w="w"><w:body>
+<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText> TOC \o "1-3" \h </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>Статья 1. Предмет</w:t></w:r></w:p>
+<w:p><w:r><w:t>Статья 2. Принципы</w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText> PAGEREF _Toc1 </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>4</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>
+<w:p><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>
+<w:p><w:r><w:t>Статья 1. Предмет</w:t></w:r></w:p>
+</w:body></w:document>`
+	lines, err := readDOCXBody(strings.NewReader(body), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var marked []bool
+	for _, line := range lines {
+		marked = append(marked, line.TOC)
+	}
+	if len(marked) != 4 || !marked[0] || !marked[1] || !marked[2] || marked[3] {
+		t.Errorf("TOC marks = %v", marked)
+	}
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DonBigBon/parser-backend/internal/models"
+)
+
+// Levels numbered through the whole act rather than restarting in every
+// parent: article 16 of chapter 3 follows article 15 of chapter 2.
+// Paragraphs, like points and clauses, start again from 1 in every chapter.
+var continuousNumbering = map[string]bool{
+	"PART":    true,
+	"SECTION": true,
+	"CHAPTER": true,
+	"ARTICLE": true,
+}
+
+var containerTypes = map[string]bool{
+	"PART":      true,
+	"SECTION":   true,
+	"CHAPTER":   true,
+	"PARAGRAPH": true,
+}
+
+type validator struct {
+	warnings  []models.Warning
+	last      map[string]*DocumentNode
+	bilingual bool
+}
+
+// Validate checks a parsed tree for signs of a misread document: gaps,
+// duplicates and reversals in numbering, containers without content and,
+// in bilingual documents, headings that lack a Kazakh name.
+func Validate(root *DocumentNode) []models.Warning {
+	v := &validator{
+		last:      make(map[string]*DocumentNode),
+		bilingual: hasKazakhNames(root),
+	}
+	v.walk(root)
+	return v.warnings
+}
+
+func (v *validator) walk(node *DocumentNode) {
+	if node.Type != "ROOT" {
+		v.checkNode(node)
+	}
+
+	seen := make(map[string]bool)
+	prev := make(map[string]*DocumentNode)
+	for _, child := range node.Children {
+		key := child.Type + " " + child.ID
+		if seen[key] {
+			v.add("duplicate", child, fmt.Sprintf("%s %s appears more than once in the same parent", child.Type, child.ID))
+		}
+		seen[key] = true
+
+		if continuousNumbering[child.Type] {
+			v.checkSequence(v.last[child.Type], child)
+			v.last[child.Type] = child
+		} else {
+			v.checkSequence(prev[child.Type], child)
+			prev[child.Type] = child
+		}
+
+		v.walk(child)
+	}
+}
+
+func (v *validator) checkNode(node *DocumentNode) {
+	if node.Status != models.StatusActive {
+		return
+	}
+	if containerTypes[node.Type] && len(node.Children) == 0 {
+		v.add("empty", node, fmt.Sprintf("%s %s has no content", node.Type, node.ID))
+	}
+	if node.Type == "ARTICLE" && len(node.Children) == 0 && node.TextRu == "" && node.TextKz == "" {
+		v.add("empty", node, fmt.Sprintf("ARTICLE %s has no text", node.ID))
+	}
+	if v.bilingual && node.NameRu != "" && node.NameKz == "" {
+		v.add("missing_kz", node, fmt.Sprintf("%s %s has no Kazakh name", node.Type, node.ID))
+	}
+}
+
+// checkSequence compares a node with the previous one of its type. Gaps are
+// only reported between plain numbers: inserted articles such as 65-1 and
+// lettered subclauses legitimately skip values.
+func (v *validator) checkSequence(prev, node *DocumentNode) {
+	if prev == nil || prev.SortKey == "" || node.SortKey == "" {
+		return
+	}
+
+	switch {
+	case node.SortKey == prev.SortKey:
+		// Repeats inside one parent are reported by walk already.
+		if prev.Parent != node.Parent {
+			v.add("duplicate", node, fmt.Sprintf("%s %s appears more than once", node.Type, node.ID))
+		}
+		return
+	case node.SortKey < prev.SortKey:
+		v.add("order", node, fmt.Sprintf("%s %s follows %s %s", node.Type, node.ID, prev.Type, prev.ID))
+		return
+	}
+
+	prevNumber, prevOK := parseArabic(prev.ID)
+	number, ok := parseArabic(node.ID)
+	if !ok {
+		return
+	}
+	if !prevOK {
+		prevNumber, prevOK = parseArabic(strings.SplitN(prev.ID, "-", 2)[0])
+	}
+	if prevOK && number > prevNumber+1 {
+		v.add("gap", node, fmt.Sprintf("%s %s follows %s %s", node.Type, node.ID, prev.Type, prev.ID))
+	}
+}
+
+func (v *validator) add(kind string, node *DocumentNode, message string) {
+	warning := models.Warning{
+		Line:     node.Line,
+		Kind:     kind,
+		NodeType: node.Type,
+		Number:   node.ID,
+		Message:  message,
+	}
+	if node.Parent != nil && node.Parent.Type != "ROOT" {
+		warning.ParentType = node.Parent.Type
+		warning.ParentNumber = node.Parent.ID
+	}
+	v.warnings = append(v.warnings, warning)
+}
+
+func hasKazakhNames(node *DocumentNode) bool {
+	if node.NameKz != "" {
+		return true
+	}
+	for _, child := range node.Children {
+		if hasKazakhNames(child) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package parser
+
+import "testing"
+
+func TestValidateReportsNumberingProblems(t *testing.T) {
+	p := NewParser()
+	root := p.ParseDocument(`Глава 1. Первая
+Статья 14. Четырнадцатая
+Текст.
+Статья 16. Шестнадцатая
+1) один;
+1) снова один;
+Статья 15. Пятнадцатая
+Текст.
+Статья 15-1. Вставленная
+Текст.
+Глава 2. Пустая`)
+
+	got := make(map[string]int)
+	for _, w := range Validate(root) {
+		got[w.Kind+" "+w.NodeType+" "+w.Number]++
+	}
+
+	want := []string{
+		"gap ARTICLE 16",
+		"duplicate CLAUSE 1",
+		"order ARTICLE 15",
+		"empty CHAPTER 2",
+	}
+	for _, key := range want {
+		if got[key] != 1 {
+			t.Errorf("%q reported %d times, want once (all: %v)", key, got[key], got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d distinct warnings, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestValidateParagraphsRestartInEveryChapter(t *testing.T) {
+	p := NewParser()
+	root := p.ParseDocument(`Глава 1. Первая
+Параграф 1. Общие положения
+Статья 1. Первая
+Текст.
+Параграф 2. Особые положения
+Статья 2. Вторая
+Текст.
+Глава 2. Вторая
+Параграф 1. Общие положения
+Статья 3. Третья
+Применяется в соответствии с параграфом 2 главы 1.
+Параграф 2. Особые положения
+Статья 4. Четвертая
+Положения параграфа 1 применяются.`)
+	if warnings := Validate(root); len(warnings) != 0 {
+		t.Errorf("warnings: %v", warnings)
+	}
+}
+
+func TestValidateMissingKazakhNamesOnlyInBilingualDocuments(t *testing.T) {
+	p := NewParser()
+	root := p.ParseDocument("Статья 1. Первая\nТекст.")
+	if warnings := Validate(root); len(warnings) != 0 {
+		t.Errorf("russian-only document: %v", warnings)
+	}
+
+	p = NewParser()
+	root = p.ParseDocument("Статья 1. Первая / Бірінші\nТекст.\nСтатья 2. Вторая\nТекст.")
+	warnings := Validate(root)
+	if len(warnings) != 1 || warnings[0].Kind != "missing_kz" || warnings[0].Number != "2" {
+		t.Errorf("bilingual document: %v", warnings)
+	}
+}